@@ -0,0 +1,54 @@
+package contentelements
+
+import (
+	"net/http"
+
+	"github.com/go-rest-framework/core"
+	"github.com/gorilla/mux"
+	"github.com/jinzhu/gorm"
+)
+
+type Contentbreadcrumbs []Contentbreadcrumb
+
+type Contentbreadcrumb struct {
+	ID    uint   `json:"id"`
+	Title string `json:"title"`
+	Urld  string `json:"urld"`
+	Kind  string `json:"kind"`
+}
+
+func breadcrumbs(db *gorm.DB, element Contentelement) Contentbreadcrumbs {
+	var res = Contentbreadcrumbs{}
+
+	for _, v := range append(ancestors(db, element), element) {
+		res = append(res, Contentbreadcrumb{
+			ID:    v.ID,
+			Title: v.Title,
+			Urld:  v.Urld,
+			Kind:  v.Kind,
+		})
+	}
+
+	return res
+}
+
+func actionAncestors(w http.ResponseWriter, r *http.Request) {
+	var (
+		element Contentelement
+		res     Contentbreadcrumbs
+		rsp     = core.Response{Data: &res, Req: r}
+		vars    = mux.Vars(r)
+	)
+
+	visible(r).Select(treeColumns).First(&element, vars["id"])
+
+	if element.ID == 0 {
+		rsp.Errors.Add("ID", "Contentelement not found")
+	} else {
+		res = breadcrumbs(visible(r), element)
+	}
+
+	rsp.Data = &res
+
+	w.Write(rsp.Make())
+}
@@ -0,0 +1,275 @@
+package contentelements
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-rest-framework/core"
+	"github.com/gorilla/mux"
+	"github.com/jinzhu/gorm"
+)
+
+// MaxCommentDepth is the number of levels a thread may have, counting the
+// top-level comment, so with 5 a reply to a comment on the fifth level is
+// rejected.
+var MaxCommentDepth = 5
+
+var commentStatuses = map[string]bool{
+	"pending":  true,
+	"approved": true,
+	"rejected": true,
+	"spam":     true,
+}
+
+type Contentmoderation struct {
+	IDs []uint `json:"ids"`
+}
+
+func migrateComments() {
+	App.DB.Model(&Contentcomment{}).
+		Where("status = '' OR status IS NULL").
+		UpdateColumn("status", "approved")
+}
+
+func checkReply(rsp *core.Response, element Contentelement, parent int) bool {
+	var (
+		depth = 0
+		id    = parent
+	)
+
+	for id != 0 {
+		var p Contentcomment
+
+		App.DB.Select("id, parent, contentelement_id, deleted").First(&p, id)
+
+		if p.ID == 0 || uint(p.ContentelementID) != element.ID {
+			rsp.Errors.Add("parent", "Parent comment not found")
+			return false
+		}
+
+		if depth == 0 && p.Deleted {
+			rsp.Errors.Add("parent", "Parent comment is deleted")
+			return false
+		}
+
+		if depth++; depth >= MaxCommentDepth {
+			rsp.Errors.Add("parent", "Comment thread is too deep")
+			return false
+		}
+
+		id = p.Parent
+	}
+
+	return true
+}
+
+func premoderated(element Contentelement) bool {
+	if element.Moderated != nil {
+		return *element.Moderated
+	}
+
+	if t, ok := FindType(element.Kind); ok && t.Moderated != nil {
+		return *t.Moderated
+	}
+
+	return false
+}
+
+func (v Viewer) moderates() bool {
+	return v.Admin || v.Moderator
+}
+
+func checkModerated(rsp *core.Response, viewer Viewer, current, next *bool) bool {
+	if next == nil || viewer.moderates() || (current != nil && *current == *next) {
+		return true
+	}
+
+	rsp.Errors.Add("moderated", "Only moderators can change comment moderation")
+
+	return false
+}
+
+func publishedComments(db *gorm.DB, viewer Viewer) *gorm.DB {
+	if viewer.moderates() {
+		return db
+	}
+
+	return db.Where("status = ? OR deleted = ? OR (user_id <> 0 AND user_id = ?)", "approved", true, viewer.ID)
+}
+
+func pruneComments(comments []Contentcomment, viewer Viewer) []Contentcomment {
+	var res = []Contentcomment{}
+
+	for _, v := range comments {
+		v.Comments = pruneComments(v.Comments, viewer)
+		if v.Deleted && len(v.Comments) == 0 {
+			continue
+		}
+		if !viewer.moderates() {
+			if !v.Deleted && v.Status != "approved" && (viewer.ID == 0 || v.UserID != viewer.ID) {
+				continue
+			}
+			if v.Deleted {
+				v.UserID = 0
+			}
+			v.Notes = ""
+		}
+		res = append(res, v)
+	}
+
+	return res
+}
+
+func hasReplies(id uint) bool {
+	var count int
+
+	App.DB.Model(&Contentcomment{}).Where("parent = ?", id).Count(&count)
+
+	return count != 0
+}
+
+func removeComment(comment Contentcomment) {
+	if App.IsTest {
+		App.DB.Unscoped().Delete(&comment)
+	} else {
+		App.DB.Delete(&comment)
+	}
+
+	removeTombstone(comment.Parent)
+}
+
+func removeTombstone(id int) {
+	var parent Contentcomment
+
+	if id == 0 {
+		return
+	}
+
+	App.DB.First(&parent, id)
+
+	if parent.ID != 0 && parent.Deleted && !hasReplies(parent.ID) {
+		removeComment(parent)
+	}
+}
+
+func commentSubtree(id uint) []uint {
+	var (
+		ids   = []uint{id}
+		level = []uint{id}
+	)
+
+	for len(level) != 0 {
+		var children Contentcomments
+
+		App.DB.Unscoped().Select("id").Where("parent IN (?)", level).Find(&children)
+
+		level = nil
+		for _, v := range children {
+			ids = append(ids, v.ID)
+			level = append(level, v.ID)
+		}
+	}
+
+	return ids
+}
+
+func actionPurgeComment(w http.ResponseWriter, r *http.Request) {
+	var (
+		comment Contentcomment
+		count   int
+		rsp     = core.Response{Data: &comment, Req: r}
+		vars    = mux.Vars(r)
+	)
+
+	App.DB.Unscoped().Where("contentelement_id = ?", vars["id"]).First(&comment, vars["cid"])
+
+	if comment.ID == 0 {
+		rsp.Errors.Add("ID", "Contentcomment not found")
+	} else if !WritePolicy.Can(viewerOf(r), "comment.purge", comment.UserID) {
+		rsp.Errors.Add("ID", "Not allowed to purge comment")
+	} else {
+		ids := commentSubtree(comment.ID)
+		App.DB.Unscoped().Where("id IN (?)", ids).Delete(&Contentcomment{})
+		App.DB.Unscoped().Where("target_type = ? AND target_id IN (?)", "comment", ids).Delete(&Contentreaction{})
+		count = len(ids)
+		removeTombstone(comment.Parent)
+	}
+
+	rsp.Data = &comment
+	rsp.Count = count
+
+	w.Write(rsp.Make())
+}
+
+func actionModerationQueue(w http.ResponseWriter, r *http.Request) {
+	var (
+		comments Contentcomments
+		count    int
+		rsp      = core.Response{Data: &comments, Req: r}
+		status   = r.FormValue("status")
+		db       = App.DB
+	)
+
+	if status == "" {
+		status = "pending"
+	}
+
+	if !WritePolicy.Can(viewerOf(r), "comment.moderate", 0) {
+		rsp.Errors.Add("ID", "Not allowed to moderate comments")
+	} else if !commentStatuses[status] {
+		rsp.Errors.Add("status", "Status "+status+" is not allowed")
+	}
+
+	if len(rsp.Errors) != 0 {
+		w.Write(rsp.Make())
+		return
+	}
+
+	db = db.Model(&Contentcomment{}).Where("status = ?", status)
+
+	if id := r.FormValue("element"); id != "" {
+		db = db.Where("contentelement_id = ?", id)
+	}
+
+	db.Count(&count)
+
+	if v, err := strconv.Atoi(r.FormValue("limit")); err == nil && v > 0 {
+		db = db.Limit(v)
+	}
+
+	if v, err := strconv.Atoi(r.FormValue("offset")); err == nil && v > 0 {
+		db = db.Offset(v)
+	}
+
+	db.Order("id").Find(&comments)
+
+	rsp.Data = &comments
+	rsp.Count = count
+
+	w.Write(rsp.Make())
+}
+
+func actionModerateComments(status string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			data     Contentmoderation
+			comments Contentcomments
+			rsp      = core.Response{Data: &data, Req: r}
+		)
+
+		if !WritePolicy.Can(viewerOf(r), "comment.moderate", 0) {
+			rsp.Errors.Add("ID", "Not allowed to moderate comments")
+		} else if rsp.IsJsonParseDone(r.Body) {
+			if len(data.IDs) == 0 {
+				rsp.Errors.Add("ids", "Comment ids are required")
+			} else {
+				App.DB.Model(&Contentcomment{}).Where("id IN (?)", data.IDs).UpdateColumn("status", status)
+				App.DB.Where("id IN (?)", data.IDs).Order("id").Find(&comments)
+				rsp.Data = &comments
+				rsp.Count = len(comments)
+			}
+		}
+
+		w.Write(rsp.Make())
+	}
+}
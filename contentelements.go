@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-rest-framework/core"
 	"github.com/gorilla/mux"
@@ -20,28 +21,43 @@ type Contenttags []Contenttag
 
 type Contentelement struct {
 	gorm.Model
-	Urld        string           `json:"urld" valid:"ascii,required"`
-	UserID      int              `json:"userID"`
-	Parent      int              `json:"parent"`
-	Title       string           `json:"title" valid:"required"`
-	Description string           `json:"description" gorm:"type:varchar(500)"`
-	Content     string           `json:"content" gorm:"type:text"`
-	Meta_title  string           `json:"meta_title"`
-	Meta_descr  string           `json:"meta_descr" gorm:"type:text"`
-	Kind        string           `json:"kind"`
-	Status      string           `json:"status" valid:"required,in(active|suspend|draft)"`
-	Tags        string           `json:"tags"`
-	Elements    []Contentelement `json:"elements" gorm:"auto_preload;foreignkey:Parent"`
-	Comments    []Contentcomment `json:"comments"`
+	Urld        string                `json:"urld" valid:"ascii,required" gorm:"unique_index:uix_contentelements_parent_urld"`
+	UserID      int                   `json:"userID"`
+	Parent      int                   `json:"parent" gorm:"unique_index:uix_contentelements_parent_urld"`
+	SortOrder   int                   `json:"sort_order"`
+	Path        string                `json:"path" gorm:"type:varchar(700);index"`
+	Title       string                `json:"title" valid:"required"`
+	Description string                `json:"description" gorm:"type:varchar(500)"`
+	Content     string                `json:"content" gorm:"type:text"`
+	Meta_title  string                `json:"meta_title"`
+	Meta_descr  string                `json:"meta_descr" gorm:"type:text"`
+	Kind        string                `json:"kind"`
+	Fields      Contentfields         `json:"fields" gorm:"type:text"`
+	Status      string                `json:"status" valid:"required"`
+	Moderated   *bool                 `json:"moderated,omitempty"`
+	PublishAt   *time.Time            `json:"publish_at"`
+	UnpublishAt *time.Time            `json:"unpublish_at"`
+	Tags        Taglist               `json:"tags" gorm:"-"`
+	Contenttags []Contenttag          `json:"-" gorm:"many2many:contentelement_tags"`
+	Elements    []Contentelement      `json:"elements" gorm:"preload:false;foreignkey:Parent"`
+	Comments    []Contentcomment      `json:"comments"`
+	Breadcrumbs []Contentbreadcrumb   `json:"breadcrumbs,omitempty" gorm:"-"`
+	Lang        string                `json:"lang,omitempty" gorm:"-"`
+	Draft       bool                  `json:"draft,omitempty" gorm:"-"`
+	Reactions   Contentreactioncounts `json:"reactions,omitempty" gorm:"-"`
 }
 
 type Contentcomment struct {
 	gorm.Model
-	Comment          string           `json:"comment" gorm:"type:varchar(500)"`
-	UserID           int              `json:"userID"`
-	Parent           int              `json:"parent"`
-	ContentelementID int              `json:"contentelementID"`
-	Comments         []Contentcomment `json:"comments" gorm:"auto_preload;foreignkey:Parent"`
+	Comment          string                `json:"comment" gorm:"type:varchar(500)"`
+	UserID           int                   `json:"userID"`
+	Parent           int                   `json:"parent"`
+	ContentelementID int                   `json:"contentelementID"`
+	Status           string                `json:"status" gorm:"index"`
+	Notes            string                `json:"notes,omitempty" gorm:"type:varchar(500)"`
+	Deleted          bool                  `json:"deleted,omitempty"`
+	Reactions        Contentreactioncounts `json:"reactions,omitempty" gorm:"-"`
+	Comments         []Contentcomment      `json:"comments" gorm:"auto_preload;foreignkey:Parent"`
 }
 
 type Contenttag struct {
@@ -60,22 +76,120 @@ type Parents []Parent
 func Configure(a core.App) {
 	App = a
 
-	App.DB.AutoMigrate(&Contentelement{}, &Contentcomment{}, &Contenttag{})
+	App.DB.AutoMigrate(&Contentelement{}, &Contentcomment{}, &Contenttag{}, &Contentelementrevision{}, &Contentredirect{}, &Contenttype{}, &Contentfieldvalue{}, &Contentelementtranslation{}, &Contenttransition{}, &Contentelementdraft{}, &Contentacl{}, &Contentreaction{})
+	migrateTags()
+	migratePaths()
+	migrateSlugs()
+	loadTypes()
+	migrateFields()
+	migrateComments()
 
-	App.R.HandleFunc("/contentelements", actionGetAll).Methods("GET")
-	App.R.HandleFunc("/contentelements/{id}", actionGetOne).Methods("GET")
+	if RoleResolver == nil {
+		RoleResolver = Tokenroles{}
+	}
 
-	App.R.HandleFunc("/contentelements", App.Protect(actionCreate, []string{"admin"})).Methods("POST")
-	App.R.HandleFunc("/contentelements/{id}", App.Protect(actionUpdate, []string{"admin"})).Methods("PATCH")
-	App.R.HandleFunc("/contentelements/{id}", App.Protect(actionDelete, []string{"admin"})).Methods("DELETE")
+	if ReadPolicy == nil {
+		ReadPolicy = Ownerpolicy{}
+	}
 
-	App.R.HandleFunc("/contentelements/{id}/comments", actionComments).Methods("GET")
-	App.R.HandleFunc("/contentelements/{id}/comments", App.Protect(actionAddComment, []string{"user"})).Methods("POST")
-	App.R.HandleFunc("/contentelements/{id}/comments/{cid}", App.Protect(actionUpdateComment, []string{"user"})).Methods("PATCH")
-	App.R.HandleFunc("/contentelements/{id}/comments/{cid}", App.Protect(actionDeleteComment, []string{"user"})).Methods("DELETE")
+	if CommentFilters == nil {
+		defaultCommentFilters()
+	}
+
+	if Index == nil {
+		Index = NewMemoryindex()
+	}
+	buildIndex()
+
+	initPreviewSecret()
+
+	startScheduler()
+
+	App.R.HandleFunc("/contentelements", optionalProtect(actionGetAll)).Methods("GET")
+	App.R.HandleFunc("/contentelements/search", optionalProtect(actionSearch)).Methods("GET")
+	App.R.HandleFunc("/contentelements/by-url/{path:.+}", optionalProtect(actionGetByUrl)).Methods("GET")
+	App.R.HandleFunc("/contentelements/resolve/{path:.+}", optionalProtect(actionResolve)).Methods("GET")
+	App.R.HandleFunc("/contentelements/translations/report", protect(actionTranslationReport, []string{"admin"})).Methods("GET")
+	App.R.HandleFunc("/contentelements/{id}", optionalProtect(actionGetOne)).Methods("GET")
+
+	App.R.HandleFunc("/contentelements", protect(actionCreate, []string{"admin"})).Methods("POST")
+	App.R.HandleFunc("/contentelements/{id}", protect(actionUpdate, AuthRoles)).Methods("PATCH")
+	App.R.HandleFunc("/contentelements/{id}", protect(actionDelete, AuthRoles)).Methods("DELETE")
+
+	App.R.HandleFunc("/contentelements/{id}/revisions", protect(actionRevisions, []string{"admin"})).Methods("GET")
+	App.R.HandleFunc("/contentelements/{id}/revisions/{rev}", protect(actionRevision, []string{"admin"})).Methods("GET")
+	App.R.HandleFunc("/contentelements/{id}/revisions/{rev}/restore", protect(actionRestoreRevision, AuthRoles)).Methods("POST")
+	App.R.HandleFunc("/contentelements/{id}/diff", protect(actionDiff, []string{"admin"})).Methods("GET")
+	App.R.HandleFunc("/contentelements/{id}/move", protect(actionMove, AuthRoles)).Methods("POST")
+	App.R.HandleFunc("/contentelements/{id}/draft", optionalProtect(actionDraft)).Methods("GET")
+	App.R.HandleFunc("/contentelements/{id}/draft", protect(actionDeleteDraft, AuthRoles)).Methods("DELETE")
+	App.R.HandleFunc("/contentelements/{id}/draft/publish", protect(actionPublishDraft, AuthRoles)).Methods("POST")
+	App.R.HandleFunc("/contentelements/{id}/preview-token", protect(actionPreviewToken, []string{"admin"})).Methods("POST")
+	App.R.HandleFunc("/contentelements/{id}/acl", protect(actionAcl, AuthRoles)).Methods("GET")
+	App.R.HandleFunc("/contentelements/{id}/acl", protect(actionAddAcl, AuthRoles)).Methods("POST")
+	App.R.HandleFunc("/contentelements/{id}/acl/{aid}", protect(actionDeleteAcl, AuthRoles)).Methods("DELETE")
+	App.R.HandleFunc("/contentelements/{id}/transition", protect(actionTransition, AuthRoles)).Methods("POST")
+	App.R.HandleFunc("/contentelements/{id}/transitions", protect(actionTransitions, []string{"admin"})).Methods("GET")
+	App.R.HandleFunc("/contentelements/{id}/ancestors", optionalProtect(actionAncestors)).Methods("GET")
+
+	App.R.HandleFunc("/contentelements/{id}/translations", optionalProtect(actionTranslations)).Methods("GET")
+	App.R.HandleFunc("/contentelements/{id}/translations", protect(actionSaveTranslation, AuthRoles)).Methods("POST")
+	App.R.HandleFunc("/contentelements/{id}/translations/{lang}", protect(actionDeleteTranslation, AuthRoles)).Methods("DELETE")
+
+	App.R.HandleFunc("/contentelements/{id}/comments", optionalProtect(actionComments)).Methods("GET")
+	App.R.HandleFunc("/contentelements/{id}/comments", protect(actionAddComment, []string{"user"})).Methods("POST")
+	App.R.HandleFunc("/contentelements/{id}/comments/{cid}", protect(actionUpdateComment, AuthRoles)).Methods("PATCH")
+	App.R.HandleFunc("/contentelements/{id}/reactions", protect(actionAddReaction, []string{"user", "admin"})).Methods("POST")
+	App.R.HandleFunc("/contentelements/{id}/reactions/{type}", protect(actionDeleteReaction, []string{"user", "admin"})).Methods("DELETE")
+	App.R.HandleFunc("/contentelements/{id}/comments/{cid}/reactions", protect(actionAddReaction, []string{"user", "admin"})).Methods("POST")
+	App.R.HandleFunc("/contentelements/{id}/comments/{cid}/reactions/{type}", protect(actionDeleteReaction, []string{"user", "admin"})).Methods("DELETE")
+	App.R.HandleFunc("/contentelements/{id}/comments/{cid}/purge", protect(actionPurgeComment, AuthRoles)).Methods("DELETE")
+	App.R.HandleFunc("/contentelements/{id}/comments/{cid}", protect(actionDeleteComment, AuthRoles)).Methods("DELETE")
+
+	App.R.HandleFunc("/contentcomments", protect(actionModerationQueue, AuthRoles)).Methods("GET")
+	App.R.HandleFunc("/contentcomments/approve", protect(actionModerateComments("approved"), AuthRoles)).Methods("POST")
+	App.R.HandleFunc("/contentcomments/reject", protect(actionModerateComments("rejected"), AuthRoles)).Methods("POST")
+	App.R.HandleFunc("/contentcomments/spam", protect(actionModerateComments("spam"), AuthRoles)).Methods("POST")
 
 	App.R.HandleFunc("/contenttags", actionTags).Methods("GET")
-	App.R.HandleFunc("/parents", actionParents).Methods("GET")
+
+	App.R.HandleFunc("/contenttypes", actionTypes).Methods("GET")
+	App.R.HandleFunc("/contenttypes/{name}", actionType).Methods("GET")
+	App.R.HandleFunc("/contenttypes", protect(actionCreateType, []string{"admin"})).Methods("POST")
+	App.R.HandleFunc("/contenttypes/{name}", protect(actionUpdateType, []string{"admin"})).Methods("PATCH")
+	App.R.HandleFunc("/contenttypes/{name}", protect(actionDeleteType, []string{"admin"})).Methods("DELETE")
+
+	App.R.HandleFunc("/contentworkflow", actionWorkflow).Methods("GET")
+
+	App.R.HandleFunc("/parents", optionalProtect(actionParents)).Methods("GET")
+}
+
+func optionalProtect(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if bearer(r) == "" {
+			r.Header.Del("id")
+			next(w, withViewer(r, Viewer{}))
+			return
+		}
+
+		roles := RoleResolver.Roles(r)
+
+		if len(roles) == 0 {
+			roles = AuthRoles
+		}
+
+		protect(next, roles)(w, r)
+	}
+}
+
+func visible(r *http.Request) *gorm.DB {
+	return visibleIn(App.DB, r)
+}
+
+func visibleIn(db *gorm.DB, r *http.Request) *gorm.DB {
+	viewer := viewerOf(r)
+
+	return aclFilter(ReadPolicy.Filter(db, viewer, "contentelements"), viewer, "contentelements")
 }
 
 func actionGetAll(w http.ResponseWriter, r *http.Request) {
@@ -95,14 +209,14 @@ func actionGetAll(w http.ResponseWriter, r *http.Request) {
 		offset      = r.FormValue("offset")
 		tags        = r.FormValue("tags")
 		status      = r.FormValue("status")
-		db          = App.DB
+		db          = visible(r)
 	)
 
 	if all != "" {
-		db = db.Where("id LIKE ?", "%"+all+"%")
-		db = db.Or("title LIKE ?", "%"+all+"%")
-		db = db.Or("description LIKE ?", "%"+all+"%")
-		db = db.Or("tags LIKE ?", "%"+all+"%")
+		db = db.Where(
+			"id LIKE ? OR title LIKE ? OR description LIKE ? OR "+tagsFilter,
+			"%"+all+"%", "%"+all+"%", "%"+all+"%", []string{all},
+		)
 	}
 
 	if id != "" {
@@ -122,13 +236,15 @@ func actionGetAll(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if tags != "" {
-		db = db.Where("tags LIKE ?", "%"+tags+"%")
+		db = db.Where(tagsFilter, []string(splitTags(tags)))
 	}
 
 	if status != "" {
 		db = db.Where("status = ?", status)
 	}
 
+	db = fieldFilters(db, r.Form)
+
 	if parent != "" || parent == "0" {
 		db = db.Where("parent = ?", parent)
 	} else {
@@ -139,9 +255,10 @@ func actionGetAll(w http.ResponseWriter, r *http.Request) {
 
 	if tree == "" || tree == "1" {
 		db = db.Set("gorm:auto_preload", true)
-		db = db.Preload("Elements")
 	}
 
+	db = db.Preload("Contenttags")
+
 	if sort != "" {
 		switch sort {
 		case "id":
@@ -168,6 +285,12 @@ func actionGetAll(w http.ResponseWriter, r *http.Request) {
 			db = db.Order("kind")
 		case "-kind":
 			db = db.Order("kind DESC")
+		case "sort_order":
+			db = db.Order("sort_order").Order("id")
+		case "-sort_order":
+			db = db.Order("sort_order DESC").Order("id DESC")
+		default:
+			db, _ = fieldOrder(db, sort)
 		}
 	} else {
 		db = db.Order("id DESC")
@@ -187,6 +310,16 @@ func actionGetAll(w http.ResponseWriter, r *http.Request) {
 
 	db.Find(&elements)
 
+	if tree == "" || tree == "1" {
+		elements = loadTree(visible(r), elements)
+	}
+
+	elements = translate(r, elements)
+
+	for i := range elements {
+		elements[i].Comments = withReactions(pruneComments(elements[i].Comments, viewerOf(r)))
+	}
+
 	rsp.Data = &elements
 	rsp.Count = count
 
@@ -203,14 +336,23 @@ func actionGetOne(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 
 	db = db.Set("gorm:auto_preload", true)
-	db = db.Preload("Elements")
 	db = db.Preload("Comments")
 
 	db.First(&element, vars["id"])
 
+	if !readable(r, element) && !checkPreview(element.ID, r.FormValue("preview")) {
+		element = Contentelement{}
+	}
+
 	if element.ID == 0 {
 		rsp.Errors.Add("ID", "Contentelement not found")
 	} else {
+		element = translate(r, loadTree(visible(r), Contentelements{element}))[0]
+		element.Comments = withReactions(pruneComments(element.Comments, viewerOf(r)))
+		element.Reactions = reactionCounts("element", []uint{element.ID})[element.ID]
+		if r.FormValue("include") == "breadcrumbs" {
+			element.Breadcrumbs = breadcrumbs(visible(r), element)
+		}
 		rsp.Data = &element
 	}
 
@@ -223,33 +365,33 @@ func actionCreate(w http.ResponseWriter, r *http.Request) {
 		rsp     = core.Response{Data: &element, Req: r}
 	)
 
-	if rsp.IsJsonParseDone(r.Body) && rsp.IsValidate() {
-		i, err := strconv.Atoi(r.Header.Get("id"))
-		if err != nil {
-			rsp.Errors.Add("json", "User getting error"+err.Error())
+	if rsp.IsJsonParseDone(r.Body) {
+		if element.Urld == "" {
+			element.Urld = uniqueSlug(element.Title, element.Parent, 0)
 		}
-		element.UserID = i
-		App.DB.Create(&element)
-	}
-
-	rsp.Data = &element
-
-	s := strings.Split(element.Tags, ",")
 
-	for _, v := range s {
-		tag := Contenttag{
-			Name:   v,
-			Weight: 1,
-		}
-		App.DB.Where("name = ?", v).First(&tag)
-		if tag.ID == 0 {
-			App.DB.Create(&tag)
-		} else {
-			tag.Weight = tag.Weight + 1
-			App.DB.Save(&tag)
+		if rsp.IsValidate() && checkModerated(&rsp, viewerOf(r), nil, element.Moderated) && checkParent(&rsp, 0, element.Parent) && checkUrld(&rsp, element.Urld, element.Parent, 0) && checkFields(&rsp, element.Kind, element.Fields) && checkStatus(&rsp, element.Status) {
+			i, err := strconv.Atoi(r.Header.Get("id"))
+			if err != nil {
+				rsp.Errors.Add("json", "User getting error"+err.Error())
+			}
+			if element.SortOrder == 0 {
+				element.SortOrder = nextSortOrder(element.Parent)
+			}
+			element.UserID = i
+			element.Path = ""
+			if !urldError(&rsp, App.DB.Create(&element).Error, element) {
+				updatePath(&element)
+				setTags(&element, element.Tags)
+				syncFields(element)
+				saveRevision(&element, i)
+				indexElement(element)
+			}
 		}
 	}
 
+	rsp.Data = &element
+
 	w.Write(rsp.Make())
 }
 
@@ -264,16 +406,66 @@ func actionUpdate(w http.ResponseWriter, r *http.Request) {
 		if rsp.IsValidate() {
 
 			vars := mux.Vars(r)
-			App.DB.First(&element, vars["id"])
+			App.DB.Preload("Contenttags").First(&element, vars["id"])
+
+			if element.Status == "active" {
+				if draft := findDraft(element.ID); draft.ID != 0 {
+					draft.applyTo(&element)
+				}
+			}
 
 			if element.ID == 0 {
 				rsp.Errors.Add("ID", "Contentelement not found")
 			} else {
-				idstring := fmt.Sprintf("%d", element.UserID)
-				if idstring != r.Header.Get("id") {
-					rsp.Errors.Add("ID", "Only owner can change element")
-				} else {
-					App.DB.Model(&element).Updates(data)
+				urld, parent, kind, fields := element.Urld, element.Parent, element.Kind, element.Fields
+				if data.Urld != "" {
+					urld = data.Urld
+				}
+				if data.Parent != 0 {
+					parent = data.Parent
+				}
+				if data.Kind != "" {
+					kind = data.Kind
+				}
+				if data.Fields != nil {
+					fields = data.Fields
+				}
+				if !canChange(r, "element.update", element) {
+					rsp.Errors.Add("ID", "Not allowed to change element")
+				} else if data.Status != "" && data.Status != element.Status {
+					rsp.Errors.Add("status", "Status can be changed only by transition")
+				} else if checkModerated(&rsp, viewerOf(r), element.Moderated, data.Moderated) && checkParent(&rsp, element.ID, parent) && checkUrld(&rsp, urld, parent, element.ID) && checkFields(&rsp, kind, fields) {
+					userID, _ := strconv.Atoi(r.Header.Get("id"))
+					if element.Status == "active" {
+						if data.Moderated != nil {
+							App.DB.Model(&element).UpdateColumn("moderated", *data.Moderated)
+						}
+						if data.PublishAt != nil {
+							App.DB.Model(&element).UpdateColumn("publish_at", *data.PublishAt)
+						}
+						if data.UnpublishAt != nil {
+							App.DB.Model(&element).UpdateColumn("unpublish_at", *data.UnpublishAt)
+						}
+						mergeDraft(&element, data)
+						saveDraft(element, userID)
+						rsp.Data = &element
+						w.Write(rsp.Make())
+						return
+					}
+					oldPath := elementPath(element)
+					data.Path = ""
+					data.Elements, data.Comments, data.Contenttags = nil, nil, nil
+					if !urldError(&rsp, App.DB.Model(&element).Updates(data).Error, Contentelement{Model: element.Model, Urld: urld, Parent: parent}) {
+						if data.Tags != nil {
+							setTags(&element, data.Tags)
+						}
+						App.DB.Preload("Contenttags").First(&element, element.ID)
+						updatePath(&element)
+						syncFields(element)
+						saveRevision(&element, userID)
+						addRedirect(oldPath, element)
+						indexElement(element)
+					}
 				}
 			}
 		}
@@ -291,16 +483,27 @@ func actionDelete(w http.ResponseWriter, r *http.Request) {
 	)
 
 	vars := mux.Vars(r)
-	App.DB.First(&element, vars["id"])
+	App.DB.Preload("Contenttags").First(&element, vars["id"])
 
 	if element.ID == 0 {
 		rsp.Errors.Add("ID", "Contentelement not found")
+	} else if !canChange(r, "element.delete", element) {
+		rsp.Errors.Add("ID", "Not allowed to delete element")
 	} else {
 		if App.IsTest {
+			App.DB.Model(&element).Association("Contenttags").Clear()
+			App.DB.Unscoped().Where("contentelement_id = ?", element.ID).Delete(&Contentfieldvalue{})
+			App.DB.Unscoped().Where("contentelement_id = ?", element.ID).Delete(&Contentelementtranslation{})
+			App.DB.Unscoped().Where("contentelement_id = ?", element.ID).Delete(&Contentelementdraft{})
+			App.DB.Unscoped().Where("contentelement_id = ?", element.ID).Delete(&Contentacl{})
+			App.DB.Unscoped().Where("target_type = ? AND target_id = ?", "element", element.ID).Delete(&Contentreaction{})
 			App.DB.Unscoped().Delete(&element)
 		} else {
+			App.DB.Model(&element).UpdateColumn("urld", deletedUrld(element))
 			App.DB.Delete(&element)
 		}
+		updateTagWeights(element.Contenttags)
+		Index.Remove(element.ID)
 	}
 
 	rsp.Data = &element
@@ -310,6 +513,7 @@ func actionDelete(w http.ResponseWriter, r *http.Request) {
 
 func actionComments(w http.ResponseWriter, r *http.Request) {
 	var (
+		element  Contentelement
 		comments Contentcomments
 		rsp      = core.Response{Data: &comments, Req: r}
 		limit    = r.FormValue("limit")
@@ -318,8 +522,19 @@ func actionComments(w http.ResponseWriter, r *http.Request) {
 	)
 
 	vars := mux.Vars(r)
-	db = db.Where("contentelement_id = ?", vars["id"])
+
+	visible(r).Select("id, path").First(&element, vars["id"])
+
+	if element.ID == 0 {
+		rsp.Errors.Add("ID", "Contentelement not found")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write(rsp.Make())
+		return
+	}
+
+	db = db.Where("contentelement_id = ?", element.ID)
 	db = db.Where("parent = ?", 0)
+	db = publishedComments(db, viewerOf(r))
 	db = db.Set("gorm:auto_preload", true)
 
 	if limit != "" {
@@ -330,8 +545,14 @@ func actionComments(w http.ResponseWriter, r *http.Request) {
 		db = db.Offset(offset)
 	}
 
+	if r.FormValue("sort") == "top" {
+		db = topComments(db)
+	}
+
 	db.Preload("Comments").Find(&comments)
 
+	comments = withReactions(pruneComments(comments, viewerOf(r)))
+
 	rsp.Data = &comments
 
 	w.Write(rsp.Make())
@@ -349,13 +570,26 @@ func actionAddComment(w http.ResponseWriter, r *http.Request) {
 
 	if element.ID == 0 {
 		rsp.Errors.Add("ID", "Contentelement not found")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write(rsp.Make())
 		return
 	}
 
-	if rsp.IsJsonParseDone(r.Body) {
-		if rsp.IsValidate() {
+	if !readable(r, element) || !allowed(viewerOf(r), element, "comment") {
+		rsp.Errors.Add("ID", "Commenting is not allowed")
+	} else if rsp.IsJsonParseDone(r.Body) {
+		if rsp.IsValidate() && checkReply(&rsp, element, comment.Parent) {
+			comment.ID, comment.Deleted = 0, false
+			comment.UserID, _ = strconv.Atoi(r.Header.Get("id"))
 			comment.ContentelementID = int(element.ID)
-			App.DB.Create(&comment)
+			comment.Status = "approved"
+			if premoderated(element) {
+				comment.Status = "pending"
+			}
+			comment.Comments = nil
+			if filterComment(&rsp, &comment, element) {
+				App.DB.Create(&comment)
+			}
 		}
 	}
 
@@ -368,6 +602,7 @@ func actionUpdateComment(w http.ResponseWriter, r *http.Request) {
 	var (
 		data    Contentcomment
 		comment Contentcomment
+		element Contentelement
 		rsp     = core.Response{Data: &data, Req: r}
 	)
 
@@ -380,11 +615,25 @@ func actionUpdateComment(w http.ResponseWriter, r *http.Request) {
 			if comment.ID == 0 {
 				rsp.Errors.Add("ID", "Comment not found")
 			} else {
-				idstring := fmt.Sprintf("%d", comment.UserID)
-				if idstring != r.Header.Get("id") {
-					rsp.Errors.Add("ID", "Only owner can change element")
+				if !WritePolicy.Can(viewerOf(r), "comment.update", comment.UserID) {
+					rsp.Errors.Add("ID", "Not allowed to change comment")
+				} else if comment.Deleted {
+					rsp.Errors.Add("ID", "Comment is deleted")
 				} else {
-					App.DB.Model(&comment).Updates(data)
+					data.ID, data.UserID, data.Parent, data.ContentelementID, data.Deleted = 0, 0, 0, 0, false
+					data.Comments = nil
+					App.DB.First(&element, comment.ContentelementID)
+					candidate := comment
+					if data.Comment != "" {
+						candidate.Comment = data.Comment
+					}
+					if premoderated(element) && !viewerOf(r).moderates() {
+						candidate.Status = "pending"
+					}
+					if filterComment(&rsp, &candidate, element) {
+						data.Status, data.Notes = candidate.Status, candidate.Notes
+						App.DB.Model(&comment).Updates(data)
+					}
 				}
 			}
 		}
@@ -406,12 +655,12 @@ func actionDeleteComment(w http.ResponseWriter, r *http.Request) {
 
 	if comment.ID == 0 {
 		rsp.Errors.Add("ID", "Contentcomment not found")
+	} else if !WritePolicy.Can(viewerOf(r), "comment.delete", comment.UserID) {
+		rsp.Errors.Add("ID", "Not allowed to delete comment")
+	} else if hasReplies(comment.ID) {
+		App.DB.Model(&comment).UpdateColumns(map[string]interface{}{"comment": "[deleted]", "notes": "", "deleted": true})
 	} else {
-		if App.IsTest {
-			App.DB.Unscoped().Delete(&comment)
-		} else {
-			App.DB.Delete(&comment)
-		}
+		removeComment(comment)
 	}
 
 	rsp.Data = &comment
@@ -443,17 +692,18 @@ func actionParents(w http.ResponseWriter, r *http.Request) {
 		elements Contentelements
 		res      Parents
 		rsp      = core.Response{Data: &res, Req: r}
-		db       = App.DB
+		db       = visible(r)
 	)
 
-	db = db.Select("id, title")
+	db = db.Select("id, title, path")
 	db = db.Where("status = ?", "active")
 	db = db.Where("parent = ?", 0)
-	db = db.Set("gorm:auto_preload", true)
-	db = db.Preload("Elements")
+	db = db.Order("sort_order").Order("id")
 
 	db.Find(&elements)
 
+	elements = translate(r, loadTree(visible(r), elements))
+
 	for _, v := range elements {
 		res = append(res, Parent{
 			Id:   v.ID,
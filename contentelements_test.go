@@ -230,7 +230,6 @@ func TestUserLogin(t *testing.T) {
 func CreateOne(t *testing.T, parent int, title string, tags string) uint {
 	url := Murl
 	el := &contentelements.Contentelement{
-		Urld:        fake.Word(),
 		Parent:      parent,
 		Title:       title,
 		Description: fake.ParagraphsN(1),
@@ -239,7 +238,7 @@ func CreateOne(t *testing.T, parent int, title string, tags string) uint {
 		Meta_descr:  fake.ParagraphsN(1),
 		Kind:        "standart",
 		Status:      "active",
-		Tags:        tags,
+		Tags:        contentelements.Taglist{tags},
 	}
 
 	uj, err := json.Marshal(el)
@@ -295,7 +294,7 @@ func TestUpdate(t *testing.T) {
 	NewTags = fake.Word()
 
 	el := &contentelements.Contentelement{
-		Urld:        fake.Word(),
+		Urld:        fmt.Sprintf("%s-%d", fake.Word(), CatId1),
 		Parent:      0,
 		UserID:      1,
 		Title:       NewsOneTitle,
@@ -305,7 +304,7 @@ func TestUpdate(t *testing.T) {
 		Meta_descr:  fake.ParagraphsN(1),
 		Kind:        "standart",
 		Status:      "active",
-		Tags:        NewTags,
+		Tags:        contentelements.Taglist{NewTags},
 	}
 
 	uj, err := json.Marshal(el)
@@ -326,6 +325,8 @@ func TestUpdate(t *testing.T) {
 		t.Fatal(u.Errors)
 	}
 
+	publishDraft(t, CatId1)
+
 	return
 }
 
@@ -365,6 +366,14 @@ func TestGetAll(t *testing.T) {
 	if len(u.Data) == 0 {
 		t.Errorf("Wrong tag search count: %d, need > 0", len(u.Data))
 	}
+	//tags match exactly, not by substring
+	u = GetOne(t, Murl+"?tags="+NewTags[:len(NewTags)-1])
+
+	for _, v := range u.Data {
+		if v.ID == CatId1 {
+			t.Errorf("Tag search matched substring of %s", NewTags)
+		}
+	}
 	//find by parent and title
 	utitle, _ = toUrlcode(NewsOneOneTitle)
 	u = GetOne(t, Murl+"?parent="+fmt.Sprintf("%d", CatId1)+"&title="+utitle)
@@ -639,6 +648,12 @@ func TestGetTags(t *testing.T) {
 		t.Errorf("Wrong comments count: %d", len(u.Data))
 	}
 
+	for _, v := range u.Data {
+		if v.Name == NewTags && v.Weight < 1 {
+			t.Errorf("Wrong weight of tag %s: %d, need >= 1", v.Name, v.Weight)
+		}
+	}
+
 	return
 }
 
@@ -0,0 +1,207 @@
+package contentelements
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-rest-framework/core"
+	"github.com/gorilla/mux"
+)
+
+type Contentdiff struct {
+	From   int                `json:"from"`
+	To     int                `json:"to"`
+	Fields []Contentfielddiff `json:"fields"`
+}
+
+type Contentfielddiff struct {
+	Field string            `json:"field"`
+	From  string            `json:"from"`
+	To    string            `json:"to"`
+	Lines []Contentlinediff `json:"lines,omitempty"`
+}
+
+type Contentlinediff struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+func (rev Contentelementrevision) fields() [][2]string {
+	fields, _ := json.Marshal(rev.Fields)
+
+	return [][2]string{
+		{"urld", rev.Urld},
+		{"parent", fmt.Sprintf("%d", rev.Parent)},
+		{"title", rev.Title},
+		{"description", rev.Description},
+		{"content", rev.Content},
+		{"meta_title", rev.Meta_title},
+		{"meta_descr", rev.Meta_descr},
+		{"kind", rev.Kind},
+		{"fields", string(fields)},
+		{"status", rev.Status},
+		{"tags", rev.Tags},
+	}
+}
+
+func diffRevisions(from, to Contentelementrevision) Contentdiff {
+	var (
+		diff = Contentdiff{From: from.Revision, To: to.Revision, Fields: []Contentfielddiff{}}
+		a    = from.fields()
+		b    = to.fields()
+	)
+
+	for i := range a {
+		if a[i][1] == b[i][1] {
+			continue
+		}
+
+		field := Contentfielddiff{
+			Field: a[i][0],
+			From:  a[i][1],
+			To:    b[i][1],
+		}
+
+		if field.Field == "content" {
+			field.Lines = diffLines(field.From, field.To)
+		}
+
+		diff.Fields = append(diff.Fields, field)
+	}
+
+	return diff
+}
+
+// MaxDiffCells caps the LCS table of a line diff. Larger changes are shown as
+// the old lines removed and the new lines added.
+var MaxDiffCells = 1 << 20
+
+func diffLines(from, to string) []Contentlinediff {
+	var (
+		a      = strings.Split(from, "\n")
+		b      = strings.Split(to, "\n")
+		lines  []Contentlinediff
+		prefix = 0
+		suffix = 0
+	)
+
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		lines = append(lines, Contentlinediff{Op: "=", Text: a[prefix]})
+		prefix++
+	}
+
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	lines = append(lines, lcsLines(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+
+	for _, v := range a[len(a)-suffix:] {
+		lines = append(lines, Contentlinediff{Op: "=", Text: v})
+	}
+
+	return lines
+}
+
+func lcsLines(a, b []string) []Contentlinediff {
+	var lines []Contentlinediff
+
+	if (len(a)+1)*(len(b)+1) > MaxDiffCells {
+		for _, v := range a {
+			lines = append(lines, Contentlinediff{Op: "-", Text: v})
+		}
+		for _, v := range b {
+			lines = append(lines, Contentlinediff{Op: "+", Text: v})
+		}
+		return lines
+	}
+
+	lcs := make([][]int, len(a)+1)
+
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, Contentlinediff{Op: "=", Text: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, Contentlinediff{Op: "-", Text: a[i]})
+			i++
+		default:
+			lines = append(lines, Contentlinediff{Op: "+", Text: b[j]})
+			j++
+		}
+	}
+
+	for ; i < len(a); i++ {
+		lines = append(lines, Contentlinediff{Op: "-", Text: a[i]})
+	}
+
+	for ; j < len(b); j++ {
+		lines = append(lines, Contentlinediff{Op: "+", Text: b[j]})
+	}
+
+	return lines
+}
+
+func actionDiff(w http.ResponseWriter, r *http.Request) {
+	var (
+		element Contentelement
+		from    Contentelementrevision
+		to      Contentelementrevision
+		diff    Contentdiff
+		rsp     = core.Response{Data: &diff, Req: r}
+		vars    = mux.Vars(r)
+	)
+
+	App.DB.First(&element, vars["id"])
+
+	if element.ID == 0 {
+		rsp.Errors.Add("ID", "Contentelement not found")
+		w.Write(rsp.Make())
+		return
+	}
+
+	from = findRevision(element.ID, r.FormValue("from"))
+
+	if r.FormValue("to") != "" {
+		to = findRevision(element.ID, r.FormValue("to"))
+	} else {
+		App.DB.Where("contentelement_id = ?", element.ID).Order("revision DESC").First(&to)
+	}
+
+	if from.ID == 0 {
+		rsp.Errors.Add("from", "Revision not found")
+	}
+
+	if to.ID == 0 {
+		rsp.Errors.Add("to", "Revision not found")
+	}
+
+	if from.ID != 0 && to.ID != 0 {
+		diff = diffRevisions(from, to)
+	}
+
+	rsp.Data = &diff
+
+	w.Write(rsp.Make())
+}
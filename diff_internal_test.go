@@ -0,0 +1,53 @@
+package contentelements
+
+import (
+	"strings"
+	"testing"
+)
+
+func joinDiff(lines []Contentlinediff) string {
+	var parts []string
+
+	for _, v := range lines {
+		parts = append(parts, v.Op+v.Text)
+	}
+
+	return strings.Join(parts, ",")
+}
+
+func TestDiffLines(t *testing.T) {
+	cases := []struct {
+		from, to string
+		want     string
+	}{
+		{"a\nb\nc", "a\nb\nc", "=a,=b,=c"},
+		{"a\nb\nc", "a\nx\nc", "=a,-b,+x,=c"},
+		{"a\nb", "a\nb\nc", "=a,=b,+c"},
+		{"a\nb\nc", "b\nc", "-a,=b,=c"},
+		{"", "a", "-,+a"},
+	}
+
+	for _, c := range cases {
+		if got := joinDiff(diffLines(c.from, c.to)); got != c.want {
+			t.Errorf("Wrong diff of %q to %q: %s, need %s", c.from, c.to, got, c.want)
+		}
+	}
+}
+
+func TestDiffLinesLimit(t *testing.T) {
+	saved := MaxDiffCells
+	MaxDiffCells = 4
+	defer func() { MaxDiffCells = saved }()
+
+	if got := joinDiff(diffLines("a\nb\nc\nd", "a\nc\nb\nd")); got != "=a,-b,-c,+c,+b,=d" {
+		t.Errorf("Wrong diff over cell limit: %s", got)
+	}
+
+	big := strings.Repeat("line\n", 100000)
+
+	MaxDiffCells = saved
+
+	if lines := diffLines("start\n"+big, "other\n"+big+"end"); len(lines) != 200004 {
+		t.Errorf("Wrong diff of large content: %d lines", len(lines))
+	}
+}
@@ -0,0 +1,70 @@
+package contentelements_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"testing"
+
+	"github.com/go-rest-framework/contentelements"
+	"github.com/go-rest-framework/core"
+)
+
+type TestContentdiff struct {
+	Errors []core.ErrorMsg             `json:"errors"`
+	Data   contentelements.Contentdiff `json:"data"`
+}
+
+func readDiffBody(r *http.Response, t *testing.T) TestContentdiff {
+	var u TestContentdiff
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		log.Fatal(err)
+	}
+	json.Unmarshal([]byte(body), &u)
+	return u
+}
+
+func TestDiff(t *testing.T) {
+	url := fmt.Sprintf("%s/%d/diff?from=1&to=2", Murl, CatId1)
+
+	resp := doRequest(url, "GET", "", AdminToken)
+
+	if resp.StatusCode != 200 {
+		t.Errorf("Success expected: %d", resp.StatusCode)
+	}
+
+	u := readDiffBody(resp, t)
+
+	if len(u.Errors) != 0 {
+		t.Fatal(u.Errors)
+	}
+
+	found := false
+	for _, f := range u.Data.Fields {
+		if f.Field == "tags" && f.From == OneTags && f.To == NewTags {
+			found = true
+		}
+		if f.Field == "content" && len(f.Lines) == 0 {
+			t.Errorf("Content diff without lines")
+		}
+	}
+
+	if !found {
+		t.Errorf("Tags change not found in diff: %v", u.Data.Fields)
+	}
+
+	url = fmt.Sprintf("%s/%d/diff?from=0", Murl, CatId1)
+
+	resp = doRequest(url, "GET", "", AdminToken)
+
+	u = readDiffBody(resp, t)
+
+	if len(u.Errors) == 0 {
+		t.Fatal("wrong revision validation dont work")
+	}
+
+	return
+}
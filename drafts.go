@@ -0,0 +1,206 @@
+package contentelements
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-rest-framework/core"
+	"github.com/gorilla/mux"
+	"github.com/jinzhu/gorm"
+)
+
+type Contentelementdraft struct {
+	gorm.Model
+	ContentelementID int           `json:"contentelementID" gorm:"unique_index"`
+	UserID           int           `json:"userID"`
+	Urld             string        `json:"urld"`
+	Parent           int           `json:"parent"`
+	Title            string        `json:"title"`
+	Description      string        `json:"description" gorm:"type:varchar(500)"`
+	Content          string        `json:"content" gorm:"type:text"`
+	Meta_title       string        `json:"meta_title"`
+	Meta_descr       string        `json:"meta_descr" gorm:"type:text"`
+	Kind             string        `json:"kind"`
+	Fields           Contentfields `json:"fields" gorm:"type:text"`
+	Tags             string        `json:"tags"`
+}
+
+func findDraft(elementID uint) Contentelementdraft {
+	var draft Contentelementdraft
+
+	App.DB.Where("contentelement_id = ?", elementID).First(&draft)
+
+	return draft
+}
+
+func saveDraft(element Contentelement, userID int) Contentelementdraft {
+	draft := findDraft(element.ID)
+
+	draft.ContentelementID = int(element.ID)
+	draft.UserID = userID
+	draft.Urld = element.Urld
+	draft.Parent = element.Parent
+	draft.Title = element.Title
+	draft.Description = element.Description
+	draft.Content = element.Content
+	draft.Meta_title = element.Meta_title
+	draft.Meta_descr = element.Meta_descr
+	draft.Kind = element.Kind
+	draft.Fields = element.Fields
+	draft.Tags = element.Tags.String()
+
+	App.DB.Save(&draft)
+
+	return draft
+}
+
+func (d Contentelementdraft) applyTo(element *Contentelement) {
+	element.Urld = d.Urld
+	element.Parent = d.Parent
+	element.Title = d.Title
+	element.Description = d.Description
+	element.Content = d.Content
+	element.Meta_title = d.Meta_title
+	element.Meta_descr = d.Meta_descr
+	element.Kind = d.Kind
+	element.Fields = d.Fields
+	element.Tags = splitTags(d.Tags)
+	element.Draft = true
+}
+
+func mergeDraft(element *Contentelement, data Contentelement) {
+	if data.Urld != "" {
+		element.Urld = data.Urld
+	}
+	if data.Parent != 0 {
+		element.Parent = data.Parent
+	}
+	if data.Title != "" {
+		element.Title = data.Title
+	}
+	if data.Description != "" {
+		element.Description = data.Description
+	}
+	if data.Content != "" {
+		element.Content = data.Content
+	}
+	if data.Meta_title != "" {
+		element.Meta_title = data.Meta_title
+	}
+	if data.Meta_descr != "" {
+		element.Meta_descr = data.Meta_descr
+	}
+	if data.Kind != "" {
+		element.Kind = data.Kind
+	}
+	if data.Fields != nil {
+		element.Fields = data.Fields
+	}
+	if data.Tags != nil {
+		element.Tags = data.Tags
+	}
+	element.Draft = true
+}
+
+func publishDraft(rsp *core.Response, element *Contentelement, draft Contentelementdraft, userID int) bool {
+	if !checkParent(rsp, element.ID, draft.Parent) || !checkUrld(rsp, draft.Urld, draft.Parent, element.ID) || !checkFields(rsp, draft.Kind, draft.Fields) {
+		return false
+	}
+
+	oldPath := elementPath(*element)
+	draft.applyTo(element)
+	if urldError(rsp, App.DB.Save(element).Error, *element) {
+		return false
+	}
+	updatePath(element)
+	setTags(element, element.Tags)
+	syncFields(*element)
+	saveRevision(element, userID)
+	addRedirect(oldPath, *element)
+	indexElement(*element)
+	App.DB.Unscoped().Delete(&draft)
+	element.Draft = false
+
+	return true
+}
+
+func actionDraft(w http.ResponseWriter, r *http.Request) {
+	var (
+		element Contentelement
+		rsp     = core.Response{Data: &element, Req: r}
+		vars    = mux.Vars(r)
+	)
+
+	App.DB.Preload("Contenttags").First(&element, vars["id"])
+
+	draft := findDraft(element.ID)
+	viewer := viewerOf(r)
+
+	if element.ID == 0 {
+		rsp.Errors.Add("ID", "Contentelement not found")
+	} else if !(viewer.Admin || viewer.ID != 0 && viewer.ID == element.UserID) && !checkPreview(element.ID, r.FormValue("preview")) {
+		element = Contentelement{}
+		rsp.Errors.Add("preview", "Wrong or expired preview token")
+	} else if draft.ID == 0 {
+		rsp.Errors.Add("ID", "Draft not found")
+	} else {
+		draft.applyTo(&element)
+	}
+
+	rsp.Data = &element
+
+	w.Write(rsp.Make())
+}
+
+func actionPublishDraft(w http.ResponseWriter, r *http.Request) {
+	var (
+		element Contentelement
+		rsp     = core.Response{Data: &element, Req: r}
+		vars    = mux.Vars(r)
+	)
+
+	App.DB.First(&element, vars["id"])
+
+	draft := findDraft(element.ID)
+
+	if element.ID == 0 {
+		rsp.Errors.Add("ID", "Contentelement not found")
+	} else if draft.ID == 0 {
+		rsp.Errors.Add("ID", "Draft not found")
+	} else if !canChange(r, "element.publish", element) {
+		rsp.Errors.Add("ID", "Not allowed to publish element")
+	} else {
+		userID, _ := strconv.Atoi(r.Header.Get("id"))
+		publishDraft(&rsp, &element, draft, userID)
+	}
+
+	rsp.Data = &element
+
+	w.Write(rsp.Make())
+}
+
+func actionDeleteDraft(w http.ResponseWriter, r *http.Request) {
+	var (
+		element Contentelement
+		rsp     = core.Response{Data: &element, Req: r}
+		vars    = mux.Vars(r)
+	)
+
+	App.DB.Preload("Contenttags").First(&element, vars["id"])
+
+	draft := findDraft(element.ID)
+
+	if element.ID == 0 {
+		rsp.Errors.Add("ID", "Contentelement not found")
+	} else if draft.ID == 0 {
+		rsp.Errors.Add("ID", "Draft not found")
+	} else if !canChange(r, "element.update", element) {
+		rsp.Errors.Add("ID", "Not allowed to change element")
+	} else {
+		App.DB.Unscoped().Delete(&draft)
+	}
+
+	rsp.Data = &element
+
+	w.Write(rsp.Make())
+}
@@ -0,0 +1,128 @@
+package contentelements_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"testing"
+
+	"github.com/go-rest-framework/contentelements"
+	"github.com/go-rest-framework/core"
+	"github.com/icrowley/fake"
+)
+
+type TestContentpreview struct {
+	Errors []core.ErrorMsg                `json:"errors"`
+	Data   contentelements.Contentpreview `json:"data"`
+}
+
+func readPreviewBody(r *http.Response, t *testing.T) TestContentpreview {
+	var u TestContentpreview
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		log.Fatal(err)
+	}
+	json.Unmarshal([]byte(body), &u)
+	return u
+}
+
+func publishDraft(t *testing.T, id uint) TestContentelement {
+	url := fmt.Sprintf("%s/%d/draft/publish", Murl, id)
+
+	resp := doRequest(url, "POST", "", AdminToken)
+
+	if resp.StatusCode != 200 {
+		t.Errorf("Success expected: %d", resp.StatusCode)
+	}
+
+	u := readElementBody(resp, t)
+
+	if len(u.Errors) != 0 {
+		t.Fatal(u.Errors)
+	}
+
+	return u
+}
+
+func TestDrafts(t *testing.T) {
+	id := CreateOne(t, int(CatId2), fake.Title(), fake.Word())
+	el := getElement(t, id)
+	liveTitle := el.Title
+	draftTitle := fake.Title()
+
+	el.Title = draftTitle
+
+	uj, err := json.Marshal(el)
+	if err != nil {
+		fmt.Printf("Error: %s", err)
+		return
+	}
+
+	resp := doRequest(fmt.Sprintf("%s/%d", Murl, id), "PATCH", string(uj), AdminToken)
+
+	u := readElementBody(resp, t)
+
+	if len(u.Errors) != 0 {
+		t.Fatal(u.Errors)
+	}
+
+	if !u.Data.Draft || u.Data.Title != draftTitle {
+		t.Errorf("Draft is not returned after update: %s", u.Data.Title)
+	}
+
+	el = getElement(t, id)
+
+	if el.Title != liveTitle {
+		t.Errorf("Live element changed before publish: %s", el.Title)
+	}
+
+	resp = doRequest(fmt.Sprintf("%s/%d/draft", Murl, id), "GET", "", "")
+
+	u = readElementBody(resp, t)
+
+	if len(u.Errors) == 0 {
+		t.Errorf("Draft is visible to anonymous reader without token")
+	}
+
+	resp = doRequest(fmt.Sprintf("%s/%d/preview-token", Murl, id), "POST", "", AdminToken)
+
+	p := readPreviewBody(resp, t)
+
+	if len(p.Errors) != 0 || p.Data.Token == "" {
+		t.Fatal(p.Errors)
+	}
+
+	resp = doRequest(fmt.Sprintf("%s/%d/draft?preview=%s", Murl, id, p.Data.Token), "GET", "", "")
+
+	u = readElementBody(resp, t)
+
+	if len(u.Errors) != 0 {
+		t.Fatal(u.Errors)
+	}
+
+	if u.Data.Title != draftTitle {
+		t.Errorf("Wrong draft title in preview: %s", u.Data.Title)
+	}
+
+	publishDraft(t, id)
+
+	el = getElement(t, id)
+
+	if el.Title != draftTitle {
+		t.Errorf("Draft is not applied on publish: %s", el.Title)
+	}
+
+	resp = doRequest(fmt.Sprintf("%s/%d/draft", Murl, id), "GET", "", AdminToken)
+
+	u = readElementBody(resp, t)
+
+	if len(u.Errors) == 0 {
+		t.Errorf("Draft is not removed after publish")
+	}
+
+	deleteElement(t, id)
+
+	return
+}
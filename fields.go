@@ -0,0 +1,206 @@
+package contentelements
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+type Contentfieldvalue struct {
+	gorm.Model
+	ContentelementID int        `json:"contentelementID" gorm:"index"`
+	Name             string     `json:"name" gorm:"index"`
+	Type             string     `json:"type"`
+	ValueString      *string    `json:"value_string" gorm:"type:varchar(500)"`
+	ValueNumber      *float64   `json:"value_number"`
+	ValueBool        *bool      `json:"value_bool"`
+	ValueTime        *time.Time `json:"value_time"`
+}
+
+var fieldOps = []string{">=", "<=", "!=", ">", "<", "="}
+
+func fieldType(kind, name string, v interface{}) string {
+	if t, ok := FindType(kind); ok {
+		for _, f := range t.Fields {
+			if f.Name == name {
+				return f.Type
+			}
+		}
+	}
+
+	switch v.(type) {
+	case float64:
+		return "float"
+	case bool:
+		return "bool"
+	}
+
+	return "string"
+}
+
+func newFieldValue(element Contentelement, name string, v interface{}) Contentfieldvalue {
+	var value = Contentfieldvalue{
+		ContentelementID: int(element.ID),
+		Name:             name,
+		Type:             fieldType(element.Kind, name, v),
+	}
+
+	switch x := v.(type) {
+	case float64:
+		value.ValueNumber = &x
+	case bool:
+		value.ValueBool = &x
+	case string:
+		if len(x) > 500 {
+			x = x[:500]
+		}
+		value.ValueString = &x
+		if value.Type == "datetime" {
+			if t, err := time.Parse(time.RFC3339, x); err == nil {
+				value.ValueTime = &t
+			}
+		}
+	default:
+		s := fmt.Sprintf("%v", x)
+		value.ValueString = &s
+	}
+
+	return value
+}
+
+func syncFields(element Contentelement) {
+	App.DB.Unscoped().Where("contentelement_id = ?", element.ID).Delete(&Contentfieldvalue{})
+
+	for name, v := range element.Fields {
+		if v == nil {
+			continue
+		}
+		value := newFieldValue(element, name, v)
+		App.DB.Create(&value)
+	}
+}
+
+func migrateFields() {
+	var (
+		count    int
+		elements Contentelements
+	)
+
+	App.DB.Model(&Contentfieldvalue{}).Count(&count)
+
+	if count != 0 {
+		return
+	}
+
+	App.DB.Select("id, kind, fields").Where("fields IS NOT NULL AND fields <> ''").Find(&elements)
+
+	for _, v := range elements {
+		syncFields(v)
+	}
+}
+
+// fieldMatch compares the filter value with the column the stored type of
+// each row is kept in, so "12345" matches a string field as text and a
+// number field as a number.
+func fieldMatch(op, value string) (string, []interface{}) {
+	var (
+		query = []string{"(type NOT IN (?) AND value_string " + op + " ?)"}
+		args  = []interface{}{[]string{"int", "float", "reference", "bool", "datetime"}, value}
+	)
+
+	if n, err := strconv.ParseFloat(value, 64); err == nil {
+		query = append(query, "(type IN (?) AND value_number "+op+" ?)")
+		args = append(args, []string{"int", "float", "reference"}, n)
+	}
+
+	if value == "true" || value == "false" {
+		query = append(query, "(type = ? AND value_bool "+op+" ?)")
+		args = append(args, "bool", value == "true")
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		query = append(query, "(type = ? AND value_time "+op+" ?)")
+		args = append(args, "datetime", t)
+	} else if t, err := time.Parse("2006-01-02", value); err == nil {
+		query = append(query, "(type = ? AND value_time "+op+" ?)")
+		args = append(args, "datetime", t)
+	}
+
+	return "(" + strings.Join(query, " OR ") + ")", args
+}
+
+func parseFieldFilter(key, value string) (name, op, arg string, ok bool) {
+	key = strings.TrimPrefix(key, "field.")
+
+	if value == "" {
+		for _, o := range fieldOps {
+			if i := strings.Index(key, o); i > 0 {
+				return key[:i], o, key[i+len(o):], true
+			}
+		}
+		return "", "", "", false
+	}
+
+	for _, o := range []string{">", "<", "!"} {
+		if strings.HasSuffix(key, o) {
+			return strings.TrimSuffix(key, o), o + "=", value, true
+		}
+	}
+
+	return key, "=", value, true
+}
+
+func fieldFilters(db *gorm.DB, form url.Values) *gorm.DB {
+	for key, values := range form {
+		if !strings.HasPrefix(key, "field.") {
+			continue
+		}
+
+		for _, value := range values {
+			name, op, arg, ok := parseFieldFilter(key, value)
+			if !ok || name == "" {
+				continue
+			}
+
+			match, args := fieldMatch(op, arg)
+
+			db = db.Where(
+				"contentelements.id IN (SELECT contentelement_id FROM contentfieldvalues WHERE deleted_at IS NULL AND name = ? AND "+match+")",
+				append([]interface{}{name}, args...)...,
+			)
+		}
+	}
+
+	return db
+}
+
+func fieldOrder(db *gorm.DB, sort string) (*gorm.DB, bool) {
+	var (
+		dir  = ""
+		name = sort
+	)
+
+	if strings.HasPrefix(name, "-") {
+		dir = " DESC"
+		name = name[1:]
+	}
+
+	if !strings.HasPrefix(name, "field.") {
+		return db, false
+	}
+
+	name = strings.TrimPrefix(name, "field.")
+
+	for _, column := range []string{"value_number", "value_time", "value_bool", "value_string"} {
+		db = db.Order(gorm.Expr(
+			"(SELECT "+column+" FROM contentfieldvalues WHERE deleted_at IS NULL AND contentelement_id = contentelements.id AND name = ? LIMIT 1)"+dir,
+			name,
+		))
+	}
+
+	return db, true
+}
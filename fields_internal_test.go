@@ -0,0 +1,42 @@
+package contentelements
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestFieldFiltersByStoredType(t *testing.T) {
+	defer testApp(t)()
+
+	element := Contentelement{Urld: "fields", Title: "Fields", Kind: "untyped", Status: "active", Fields: Contentfields{
+		"zip":   "12345",
+		"code":  "true",
+		"price": float64(10),
+		"sale":  true,
+	}}
+	App.DB.Create(&element)
+	syncFields(element)
+
+	cases := []struct {
+		filter url.Values
+		found  bool
+	}{
+		{url.Values{"field.zip": {"12345"}}, true},
+		{url.Values{"field.code": {"true"}}, true},
+		{url.Values{"field.price": {"10"}}, true},
+		{url.Values{"field.price>": {"5"}}, true},
+		{url.Values{"field.price>": {"50"}}, false},
+		{url.Values{"field.sale": {"true"}}, true},
+		{url.Values{"field.sale": {"false"}}, false},
+	}
+
+	for _, c := range cases {
+		var count int
+
+		fieldFilters(App.DB.Model(&Contentelement{}), c.filter).Count(&count)
+
+		if (count == 1) != c.found {
+			t.Errorf("Wrong result of filter %v: %d elements", c.filter, count)
+		}
+	}
+}
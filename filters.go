@@ -0,0 +1,142 @@
+package contentelements
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-rest-framework/core"
+)
+
+const (
+	FilterAccept   = ""
+	FilterReject   = "reject"
+	FilterFlag     = "flag"
+	FilterAnnotate = "annotate"
+)
+
+var linkRe = regexp.MustCompile(`(?i)(https?://|www\.)`)
+
+type Commentverdict struct {
+	Action string
+	Reason string
+}
+
+type Commentfilter interface {
+	Check(comment Contentcomment, element Contentelement) Commentverdict
+}
+
+var CommentFilters []Commentfilter
+
+type Bannedwords struct {
+	Words  []string
+	Action string
+}
+
+type Linklimit struct {
+	Max    int
+	Action string
+}
+
+type Duplicatefilter struct {
+	Window time.Duration
+	Action string
+}
+
+type Ratelimit struct {
+	Max    int
+	Window time.Duration
+	Action string
+}
+
+func RegisterCommentFilter(f Commentfilter) {
+	CommentFilters = append(CommentFilters, f)
+}
+
+func defaultCommentFilters() {
+	RegisterCommentFilter(Bannedwords{Action: FilterReject})
+	RegisterCommentFilter(Linklimit{Max: 3, Action: FilterFlag})
+	RegisterCommentFilter(Duplicatefilter{Window: time.Hour, Action: FilterReject})
+	RegisterCommentFilter(Ratelimit{Max: 10, Window: time.Minute, Action: FilterReject})
+}
+
+func (f Bannedwords) Check(comment Contentcomment, element Contentelement) Commentverdict {
+	words := strings.FieldsFunc(strings.ToLower(comment.Comment), func(c rune) bool {
+		return !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c > 127)
+	})
+
+	for _, w := range words {
+		for _, banned := range f.Words {
+			if w == strings.ToLower(banned) {
+				return Commentverdict{Action: f.Action, Reason: "Comment contains banned word " + banned}
+			}
+		}
+	}
+
+	return Commentverdict{}
+}
+
+func (f Linklimit) Check(comment Contentcomment, element Contentelement) Commentverdict {
+	if len(linkRe.FindAllString(comment.Comment, -1)) > f.Max {
+		return Commentverdict{Action: f.Action, Reason: "Comment contains too many links"}
+	}
+
+	return Commentverdict{}
+}
+
+func (f Duplicatefilter) Check(comment Contentcomment, element Contentelement) Commentverdict {
+	var count int
+
+	App.DB.Model(&Contentcomment{}).
+		Where("id <> ? AND user_id = ? AND comment = ? AND created_at > ?", comment.ID, comment.UserID, comment.Comment, Now().Add(-f.Window)).
+		Count(&count)
+
+	if count != 0 {
+		return Commentverdict{Action: f.Action, Reason: "Duplicate comment"}
+	}
+
+	return Commentverdict{}
+}
+
+func (f Ratelimit) Check(comment Contentcomment, element Contentelement) Commentverdict {
+	var count int
+
+	if comment.ID != 0 {
+		return Commentverdict{}
+	}
+
+	App.DB.Model(&Contentcomment{}).
+		Where("user_id = ? AND created_at > ?", comment.UserID, Now().Add(-f.Window)).
+		Count(&count)
+
+	if count >= f.Max {
+		return Commentverdict{Action: f.Action, Reason: "Too many comments, try again later"}
+	}
+
+	return Commentverdict{}
+}
+
+func filterComment(rsp *core.Response, comment *Contentcomment, element Contentelement) bool {
+	var notes []string
+
+	for _, f := range CommentFilters {
+		v := f.Check(*comment, element)
+
+		switch v.Action {
+		case FilterReject:
+			rsp.Errors.Add("comment", v.Reason)
+			return false
+		case FilterFlag:
+			if comment.Status == "approved" {
+				comment.Status = "pending"
+			}
+			notes = append(notes, v.Reason)
+		case FilterAnnotate:
+			notes = append(notes, v.Reason)
+		}
+	}
+
+	comment.Notes = strings.Join(notes, "; ")
+
+	return true
+}
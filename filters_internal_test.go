@@ -0,0 +1,31 @@
+package contentelements
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFilterActions(t *testing.T) {
+	defer testApp(t)()
+
+	App.DB.Create(&Contentcomment{Comment: "Same text", UserID: 7, ContentelementID: 1, Status: "approved"})
+
+	comment := Contentcomment{Comment: "Same text", UserID: 7, ContentelementID: 1}
+
+	cases := []struct {
+		filter Commentfilter
+		action string
+	}{
+		{Duplicatefilter{Window: time.Hour, Action: FilterFlag}, FilterFlag},
+		{Duplicatefilter{Window: time.Hour, Action: FilterReject}, FilterReject},
+		{Ratelimit{Max: 1, Window: time.Minute, Action: FilterAnnotate}, FilterAnnotate},
+		{Ratelimit{Max: 1, Window: time.Minute, Action: FilterReject}, FilterReject},
+		{Ratelimit{Max: 2, Window: time.Minute, Action: FilterReject}, FilterAccept},
+	}
+
+	for _, c := range cases {
+		if v := c.filter.Check(comment, Contentelement{}); v.Action != c.action {
+			t.Errorf("Wrong action of %+v: %q, need %q", c.filter, v.Action, c.action)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package contentelements_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/go-rest-framework/contentelements"
+	"github.com/icrowley/fake"
+)
+
+func TestCommentFilterRules(t *testing.T) {
+	var (
+		element = contentelements.Contentelement{}
+		banned  = contentelements.Bannedwords{Words: []string{"Casino"}, Action: contentelements.FilterReject}
+		links   = contentelements.Linklimit{Max: 1, Action: contentelements.FilterFlag}
+	)
+
+	v := banned.Check(contentelements.Contentcomment{Comment: "Best CASINO, here!"}, element)
+
+	if v.Action != contentelements.FilterReject {
+		t.Errorf("Banned word is not rejected: %+v", v)
+	}
+
+	v = banned.Check(contentelements.Contentcomment{Comment: "Casinos are not banned"}, element)
+
+	if v.Action != contentelements.FilterAccept {
+		t.Errorf("Word containing banned word is rejected: %+v", v)
+	}
+
+	v = links.Check(contentelements.Contentcomment{Comment: "see http://a.example and www.b.example"}, element)
+
+	if v.Action != contentelements.FilterFlag {
+		t.Errorf("Too many links are not flagged: %+v", v)
+	}
+
+	v = links.Check(contentelements.Contentcomment{Comment: "see https://a.example"}, element)
+
+	if v.Action != contentelements.FilterAccept {
+		t.Errorf("Single link is flagged: %+v", v)
+	}
+
+	return
+}
+
+func TestCommentFilters(t *testing.T) {
+	url := fmt.Sprintf("%s/%d/comments", Murl, NewsOneId)
+	text := fmt.Sprintf("%s http://a.example http://b.example http://c.example http://d.example", fake.Word())
+
+	resp := doRequest(url, "POST", fmt.Sprintf(`{"comment":"%s"}`, text), UserToken)
+
+	if resp.StatusCode != 200 {
+		t.Errorf("Success expected: %d", resp.StatusCode)
+	}
+
+	c := readCommentBody(resp, t)
+
+	if len(c.Errors) != 0 {
+		t.Fatal(c.Errors)
+	}
+
+	if c.Data.Status != "pending" || c.Data.Notes == "" {
+		t.Errorf("Comment with many links is not flagged: %s", c.Data.Status)
+	}
+
+	resp = doRequest(url, "POST", fmt.Sprintf(`{"comment":"%s"}`, text), UserToken)
+
+	c = readCommentBody(resp, t)
+
+	if len(c.Errors) == 0 {
+		t.Errorf("Duplicate comment is not rejected")
+	}
+
+	return
+}
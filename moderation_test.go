@@ -0,0 +1,146 @@
+package contentelements_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/go-rest-framework/contentelements"
+	"github.com/icrowley/fake"
+)
+
+var Curl = "http://localhost/api/contentcomments"
+
+func TestModeration(t *testing.T) {
+	moderated := true
+
+	el := &contentelements.Contentelement{
+		Title:     fake.Title(),
+		Kind:      "standart",
+		Status:    "active",
+		Moderated: &moderated,
+	}
+
+	uj, err := json.Marshal(el)
+	if err != nil {
+		fmt.Printf("Error: %s", err)
+		return
+	}
+
+	resp := doRequest(Murl, "POST", string(uj), AdminToken)
+
+	if resp.StatusCode != 200 {
+		t.Errorf("Success expected: %d", resp.StatusCode)
+	}
+
+	u := readElementBody(resp, t)
+
+	if len(u.Errors) != 0 {
+		t.Fatal(u.Errors)
+	}
+
+	id := u.Data.ID
+	url := fmt.Sprintf("%s/%d/comments", Murl, id)
+
+	resp = doRequest(url, "POST", fmt.Sprintf(`{"comment":"%s","status":"approved"}`, fake.Word()), UserToken)
+
+	c := readCommentBody(resp, t)
+
+	if len(c.Errors) != 0 {
+		t.Fatal(c.Errors)
+	}
+
+	if c.Data.Status != "pending" {
+		t.Errorf("Wrong status of premoderated comment: %s", c.Data.Status)
+	}
+
+	resp = doRequest(url, "GET", "", "")
+
+	list := readCommentsBody(resp, t)
+
+	if len(list.Data) != 0 {
+		t.Errorf("Pending comment is visible to anonymous reader")
+	}
+
+	utitle, _ := toUrlcode(el.Title)
+
+	resp = doRequest(Murl+"?title="+utitle, "GET", "", "")
+
+	elements := readElementsBody(resp, t)
+
+	if len(elements.Data) != 1 {
+		t.Fatalf("Element is not listed: %d", len(elements.Data))
+	}
+
+	if len(elements.Data[0].Comments) != 0 {
+		t.Errorf("Pending comment is visible in element list: %+v", elements.Data[0].Comments)
+	}
+
+	resp = doRequest(fmt.Sprintf("%s?status=pending&element=%d", Curl, id), "GET", "", AdminToken)
+
+	list = readCommentsBody(resp, t)
+
+	if len(list.Errors) != 0 {
+		t.Fatal(list.Errors)
+	}
+
+	if len(list.Data) != 1 || list.Data[0].ID != c.Data.ID {
+		t.Errorf("Pending comment is not in moderation queue")
+	}
+
+	resp = doRequest(Curl+"/approve", "POST", fmt.Sprintf(`{"ids":[%d]}`, c.Data.ID), AdminToken)
+
+	list = readCommentsBody(resp, t)
+
+	if len(list.Errors) != 0 {
+		t.Fatal(list.Errors)
+	}
+
+	resp = doRequest(url, "GET", "", "")
+
+	list = readCommentsBody(resp, t)
+
+	if len(list.Data) != 1 || list.Data[0].Status != "approved" {
+		t.Errorf("Approved comment is not visible to anonymous reader")
+	}
+
+	resp = doRequest(fmt.Sprintf("%s/%d", url, c.Data.ID), "PATCH", fmt.Sprintf(`{"comment":"%s"}`, fake.Word()), UserToken)
+
+	c = readCommentBody(resp, t)
+
+	if len(c.Errors) != 0 {
+		t.Fatal(c.Errors)
+	}
+
+	if c.Data.Status != "pending" {
+		t.Errorf("Edited comment is not returned to moderation: %s", c.Data.Status)
+	}
+
+	resp = doRequest(url, "GET", "", "")
+
+	list = readCommentsBody(resp, t)
+
+	if len(list.Data) != 0 {
+		t.Errorf("Edited comment is visible before approval")
+	}
+
+	resp = doRequest(Curl+"/reject", "POST", fmt.Sprintf(`{"ids":[%d]}`, c.Data.ID), AdminToken)
+
+	list = readCommentsBody(resp, t)
+
+	if len(list.Errors) != 0 {
+		t.Fatal(list.Errors)
+	}
+
+	resp = doRequest(url, "GET", "", "")
+
+	list = readCommentsBody(resp, t)
+
+	if len(list.Data) != 0 {
+		t.Errorf("Rejected comment is visible to anonymous reader")
+	}
+
+	deleteElement(t, id)
+
+	return
+}
@@ -0,0 +1,118 @@
+package contentelements
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-rest-framework/core"
+	"github.com/gorilla/mux"
+)
+
+type Contentmove struct {
+	Parent   int `json:"parent"`
+	Position int `json:"position"`
+}
+
+func checkParent(rsp *core.Response, id uint, parent int) bool {
+	var p Contentelement
+
+	if parent == 0 {
+		return true
+	}
+
+	if uint(parent) == id {
+		rsp.Errors.Add("parent", "Element can not be its own parent")
+		return false
+	}
+
+	App.DB.Select("id, parent, path").First(&p, parent)
+
+	if p.ID == 0 {
+		rsp.Errors.Add("parent", "Parent element not found")
+		return false
+	}
+
+	if id != 0 && strings.Contains(p.Path, fmt.Sprintf("/%d/", id)) {
+		rsp.Errors.Add("parent", "Element can not be moved into its own descendant")
+		return false
+	}
+
+	return true
+}
+
+func nextSortOrder(parent int) int {
+	var count int
+
+	App.DB.Model(&Contentelement{}).Where("parent = ?", parent).Count(&count)
+
+	return count
+}
+
+func placeElement(element *Contentelement, parent, position int) {
+	var siblings Contentelements
+
+	App.DB.Select("id, sort_order").
+		Where("parent = ? AND id <> ?", parent, element.ID).
+		Order("sort_order, id").
+		Find(&siblings)
+
+	if position < 0 || position > len(siblings) {
+		position = len(siblings)
+	}
+
+	for i, v := range siblings {
+		order := i
+		if i >= position {
+			order = i + 1
+		}
+		if v.SortOrder != order {
+			App.DB.Model(&v).UpdateColumn("sort_order", order)
+		}
+	}
+
+	element.Parent = parent
+	element.SortOrder = position
+
+	App.DB.Model(element).UpdateColumns(map[string]interface{}{
+		"parent":     parent,
+		"sort_order": position,
+	})
+}
+
+// actionMove changes the tree placement live even for active elements: sort
+// order is not part of a draft. A pending draft follows the new parent so
+// publishing it does not move the element back.
+func actionMove(w http.ResponseWriter, r *http.Request) {
+	var (
+		move    Contentmove
+		element Contentelement
+		rsp     = core.Response{Data: &move, Req: r}
+		vars    = mux.Vars(r)
+	)
+
+	if rsp.IsJsonParseDone(r.Body) {
+		App.DB.Preload("Contenttags").First(&element, vars["id"])
+
+		if element.ID == 0 {
+			rsp.Errors.Add("ID", "Contentelement not found")
+		} else if !canChange(r, "element.update", element) {
+			rsp.Errors.Add("ID", "Not allowed to change element")
+		} else if checkParent(&rsp, element.ID, move.Parent) && checkUrld(&rsp, element.Urld, move.Parent, element.ID) {
+			userID, _ := strconv.Atoi(r.Header.Get("id"))
+			oldPath := elementPath(element)
+			placeElement(&element, move.Parent, move.Position)
+			if draft := findDraft(element.ID); draft.ID != 0 {
+				App.DB.Model(&draft).UpdateColumn("parent", element.Parent)
+			}
+			updatePath(&element)
+			saveRevision(&element, userID)
+			addRedirect(oldPath, element)
+		}
+	}
+
+	rsp.Data = &element
+
+	w.Write(rsp.Make())
+}
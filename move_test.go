@@ -0,0 +1,111 @@
+package contentelements_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/icrowley/fake"
+)
+
+func moveElement(t *testing.T, id uint, parent uint, position int) TestContentelement {
+	url := fmt.Sprintf("%s/%d/move", Murl, id)
+
+	resp := doRequest(url, "POST", fmt.Sprintf(`{"parent":%d,"position":%d}`, parent, position), AdminToken)
+
+	if resp.StatusCode != 200 {
+		t.Errorf("Success expected: %d", resp.StatusCode)
+	}
+
+	return readElementBody(resp, t)
+}
+
+func TestMove(t *testing.T) {
+	u := moveElement(t, NewsTwoId, CatId1, 0)
+
+	if len(u.Errors) != 0 {
+		t.Fatal(u.Errors)
+	}
+
+	cat := getElement(t, CatId1)
+
+	if len(cat.Elements) == 0 || cat.Elements[0].ID != NewsTwoId {
+		t.Errorf("Element %d was not moved to first position", NewsTwoId)
+	}
+
+	u = moveElement(t, NewsTwoId, CatId1, 1)
+
+	if len(u.Errors) != 0 {
+		t.Fatal(u.Errors)
+	}
+
+	cat = getElement(t, CatId1)
+
+	if len(cat.Elements) < 2 || cat.Elements[1].ID != NewsTwoId {
+		t.Errorf("Element %d was not moved to second position", NewsTwoId)
+	}
+
+	return
+}
+
+func TestMoveValidation(t *testing.T) {
+	u := moveElement(t, CatId1, NewsOneId, 0)
+
+	if len(u.Errors) == 0 {
+		t.Errorf("Move into own descendant is not rejected")
+	}
+
+	u = moveElement(t, CatId1, CatId1, 0)
+
+	if len(u.Errors) == 0 {
+		t.Errorf("Move into itself is not rejected")
+	}
+
+	u = moveElement(t, NewsOneId, 0xFFFFFFF, 0)
+
+	if len(u.Errors) == 0 {
+		t.Errorf("Move to nonexistent parent is not rejected")
+	}
+
+	return
+}
+
+func TestMoveWithDraft(t *testing.T) {
+	id := CreateOne(t, int(CatId2), fake.Title(), fake.Word())
+	el := getElement(t, id)
+	el.Title = fake.Title()
+
+	uj, err := json.Marshal(el)
+	if err != nil {
+		fmt.Printf("Error: %s", err)
+		return
+	}
+
+	resp := doRequest(fmt.Sprintf("%s/%d", Murl, id), "PATCH", string(uj), AdminToken)
+
+	u := readElementBody(resp, t)
+
+	if len(u.Errors) != 0 || !u.Data.Draft {
+		t.Fatal(u.Errors)
+	}
+
+	u = moveElement(t, id, CatId1, 0)
+
+	if len(u.Errors) != 0 {
+		t.Fatal(u.Errors)
+	}
+
+	if moved := getElement(t, id); moved.Parent != int(CatId1) {
+		t.Errorf("Move of element with draft is not applied live: %d", moved.Parent)
+	}
+
+	publishDraft(t, id)
+
+	if published := getElement(t, id); published.Parent != int(CatId1) || published.Title != el.Title {
+		t.Errorf("Publishing draft reverted move: parent %d, title %s", published.Parent, published.Title)
+	}
+
+	deleteElement(t, id)
+
+	return
+}
@@ -0,0 +1,201 @@
+package contentelements
+
+import (
+	"net/http"
+
+	"github.com/go-rest-framework/core"
+	"github.com/gorilla/mux"
+	"github.com/jinzhu/gorm"
+)
+
+var aclPermissions = map[string]bool{
+	"read":    true,
+	"write":   true,
+	"comment": true,
+}
+
+type Contentacls []Contentacl
+
+type Contentacl struct {
+	gorm.Model
+	ContentelementID int    `json:"contentelementID" gorm:"index"`
+	Role             string `json:"role"`
+	UserID           int    `json:"userID"`
+	Permission       string `json:"permission" valid:"required"`
+}
+
+func (v Viewer) roles() []string {
+	if v.ID == 0 {
+		return []string{"anonymous"}
+	}
+
+	if hasState("user", v.Roles) {
+		return v.Roles
+	}
+
+	return append([]string{"user"}, v.Roles...)
+}
+
+func (a Contentacl) Matches(viewer Viewer) bool {
+	if a.UserID != 0 {
+		return a.UserID == viewer.ID
+	}
+
+	for _, role := range viewer.roles() {
+		if a.Role == role {
+			return true
+		}
+	}
+
+	return false
+}
+
+func allowed(viewer Viewer, element Contentelement, permission string) bool {
+	var (
+		list    Contentacls
+		granted = map[int]bool{}
+	)
+
+	if viewer.Admin {
+		return true
+	}
+
+	ids := pathIDs(element.Path)
+
+	if len(ids) == 0 {
+		ids = []uint{element.ID}
+	}
+
+	App.DB.Where("contentelement_id IN (?) AND permission = ?", ids, permission).Find(&list)
+
+	for _, a := range list {
+		granted[a.ContentelementID] = granted[a.ContentelementID] || a.Matches(viewer)
+	}
+
+	for _, ok := range granted {
+		if !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+func grants(viewer Viewer, element Contentelement, permission string) bool {
+	var list Contentacls
+
+	if viewer.ID == 0 {
+		return false
+	}
+
+	ids := pathIDs(element.Path)
+
+	if len(ids) == 0 {
+		ids = []uint{element.ID}
+	}
+
+	App.DB.Where("contentelement_id IN (?) AND permission = ?", ids, permission).Find(&list)
+
+	for _, a := range list {
+		if a.Matches(viewer) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func aclFilter(db *gorm.DB, viewer Viewer, table string) *gorm.DB {
+	if viewer.Admin {
+		return db
+	}
+
+	return db.Where(
+		"NOT EXISTS (SELECT 1 FROM contentacls a WHERE a.deleted_at IS NULL AND a.permission = ? AND "+table+".path LIKE CONCAT('%/', a.contentelement_id, '/%') AND NOT EXISTS (SELECT 1 FROM contentacls b WHERE b.deleted_at IS NULL AND b.contentelement_id = a.contentelement_id AND b.permission = ? AND ((b.user_id = 0 AND b.role IN (?)) OR (b.user_id <> 0 AND b.user_id = ?))))",
+		"read", "read", viewer.roles(), viewer.ID,
+	)
+}
+
+func readable(r *http.Request, element Contentelement) bool {
+	viewer := viewerOf(r)
+
+	return ReadPolicy.CanRead(viewer, element) && allowed(viewer, element, "read")
+}
+
+func actionAcl(w http.ResponseWriter, r *http.Request) {
+	var (
+		element Contentelement
+		list    Contentacls
+		rsp     = core.Response{Data: &list, Req: r}
+		vars    = mux.Vars(r)
+	)
+
+	App.DB.First(&element, vars["id"])
+
+	if element.ID == 0 {
+		rsp.Errors.Add("ID", "Contentelement not found")
+	} else if !canChange(r, "element.acl", element) {
+		rsp.Errors.Add("ID", "Not allowed to change element")
+	} else {
+		App.DB.Where("contentelement_id = ?", element.ID).Order("id").Find(&list)
+	}
+
+	rsp.Data = &list
+
+	w.Write(rsp.Make())
+}
+
+func actionAddAcl(w http.ResponseWriter, r *http.Request) {
+	var (
+		element Contentelement
+		acl     Contentacl
+		rsp     = core.Response{Data: &acl, Req: r}
+		vars    = mux.Vars(r)
+	)
+
+	if rsp.IsJsonParseDone(r.Body) && rsp.IsValidate() {
+		App.DB.First(&element, vars["id"])
+
+		if element.ID == 0 {
+			rsp.Errors.Add("ID", "Contentelement not found")
+		} else if !canChange(r, "element.acl", element) {
+			rsp.Errors.Add("ID", "Not allowed to change element")
+		} else if !aclPermissions[acl.Permission] {
+			rsp.Errors.Add("permission", "Permission "+acl.Permission+" is not allowed")
+		} else if (acl.Role == "") == (acl.UserID == 0) {
+			rsp.Errors.Add("role", "Either role or userID is required")
+		} else {
+			acl.ID = 0
+			acl.ContentelementID = int(element.ID)
+			App.DB.Create(&acl)
+		}
+	}
+
+	rsp.Data = &acl
+
+	w.Write(rsp.Make())
+}
+
+func actionDeleteAcl(w http.ResponseWriter, r *http.Request) {
+	var (
+		element Contentelement
+		acl     Contentacl
+		rsp     = core.Response{Data: &acl, Req: r}
+		vars    = mux.Vars(r)
+	)
+
+	App.DB.First(&element, vars["id"])
+	App.DB.Where("contentelement_id = ?", vars["id"]).First(&acl, vars["aid"])
+
+	if acl.ID == 0 {
+		rsp.Errors.Add("aid", "Access entry not found")
+	} else if !canChange(r, "element.acl", element) {
+		rsp.Errors.Add("ID", "Not allowed to change element")
+	} else {
+		App.DB.Unscoped().Delete(&acl)
+	}
+
+	rsp.Data = &acl
+
+	w.Write(rsp.Make())
+}
@@ -0,0 +1,144 @@
+package contentelements_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"testing"
+
+	"github.com/go-rest-framework/contentelements"
+	"github.com/go-rest-framework/core"
+	"github.com/icrowley/fake"
+)
+
+type TestContentacl struct {
+	Errors []core.ErrorMsg            `json:"errors"`
+	Data   contentelements.Contentacl `json:"data"`
+}
+
+func readAclBody(r *http.Response, t *testing.T) TestContentacl {
+	var u TestContentacl
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		log.Fatal(err)
+	}
+	json.Unmarshal([]byte(body), &u)
+	return u
+}
+
+func addAcl(t *testing.T, id uint, role, permission string) TestContentacl {
+	url := fmt.Sprintf("%s/%d/acl", Murl, id)
+
+	resp := doRequest(url, "POST", fmt.Sprintf(`{"role":"%s","permission":"%s"}`, role, permission), AdminToken)
+
+	if resp.StatusCode != 200 {
+		t.Errorf("Success expected: %d", resp.StatusCode)
+	}
+
+	return readAclBody(resp, t)
+}
+
+func TestAcl(t *testing.T) {
+	section := CreateOne(t, 0, fake.Title(), fake.Word())
+	child := CreateOne(t, int(section), fake.Title(), fake.Word())
+	childUrl := fmt.Sprintf("%s/%d", Murl, child)
+
+	a := addAcl(t, section, "user", "read")
+
+	if len(a.Errors) != 0 {
+		t.Fatal(a.Errors)
+	}
+
+	resp := doRequest(childUrl, "GET", "", "")
+
+	u := readElementBody(resp, t)
+
+	if len(u.Errors) == 0 {
+		t.Errorf("Element of member section is visible to anonymous reader")
+	}
+
+	resp = doRequest(fmt.Sprintf("%s?parent=%d", Murl, section), "GET", "", "")
+
+	list := readElementsBody(resp, t)
+
+	if len(list.Data) != 0 {
+		t.Errorf("Element of member section is listed for anonymous reader")
+	}
+
+	resp = doRequest(childUrl+"/comments", "GET", "", "")
+
+	c := readCommentsBody(resp, t)
+
+	if len(c.Errors) == 0 {
+		t.Errorf("Comments of member section are visible to anonymous reader")
+	}
+
+	resp = doRequest(childUrl, "GET", "", UserToken)
+
+	u = readElementBody(resp, t)
+
+	if len(u.Errors) != 0 {
+		t.Fatal(u.Errors)
+	}
+
+	a = addAcl(t, child, "anonymous", "comment")
+
+	if len(a.Errors) != 0 {
+		t.Fatal(a.Errors)
+	}
+
+	resp = doRequest(childUrl+"/comments", "POST", fmt.Sprintf(`{"comment":"%s"}`, fake.Word()), UserToken)
+
+	cm := readCommentBody(resp, t)
+
+	if len(cm.Errors) == 0 {
+		t.Errorf("Comment is accepted without comment permission")
+	}
+
+	a = addAcl(t, child, "", "read")
+
+	if len(a.Errors) == 0 {
+		t.Errorf("Access entry without role and user is accepted")
+	}
+
+	deleteElement(t, child)
+	deleteElement(t, section)
+
+	return
+}
+
+func TestAclRoles(t *testing.T) {
+	var (
+		partner   = contentelements.Viewer{ID: 7, Roles: []string{"partner"}}
+		moderator = contentelements.Viewer{ID: 8, Roles: []string{"moderator"}, Moderator: true}
+		member    = contentelements.Viewer{ID: 9}
+		anonymous = contentelements.Viewer{}
+	)
+
+	cases := []struct {
+		acl     contentelements.Contentacl
+		viewer  contentelements.Viewer
+		matches bool
+	}{
+		{contentelements.Contentacl{Role: "partner"}, partner, true},
+		{contentelements.Contentacl{Role: "partner"}, member, false},
+		{contentelements.Contentacl{Role: "partner"}, anonymous, false},
+		{contentelements.Contentacl{Role: "user"}, partner, true},
+		{contentelements.Contentacl{Role: "moderator"}, moderator, true},
+		{contentelements.Contentacl{Role: "moderator"}, partner, false},
+		{contentelements.Contentacl{Role: "anonymous"}, anonymous, true},
+		{contentelements.Contentacl{Role: "anonymous"}, member, false},
+		{contentelements.Contentacl{UserID: 9}, member, true},
+		{contentelements.Contentacl{UserID: 9}, partner, false},
+	}
+
+	for _, c := range cases {
+		if got := c.acl.Matches(c.viewer); got != c.matches {
+			t.Errorf("Wrong match of %+v for %+v: %v, need %v", c.acl, c.viewer, got, c.matches)
+		}
+	}
+
+	return
+}
@@ -0,0 +1,181 @@
+package contentelements
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/jinzhu/gorm"
+)
+
+type Viewer struct {
+	ID        int
+	Roles     []string
+	Admin     bool
+	Moderator bool
+}
+
+type viewerKey struct{}
+
+// Roleresolver tells which roles the bearer of an already verified token has.
+type Roleresolver interface {
+	Roles(r *http.Request) []string
+}
+
+// Tokenroles reads the "role" and "roles" claims of the JWT payload.
+type Tokenroles struct{}
+
+var RoleResolver Roleresolver
+
+// AuthRoles are accepted on read routes when the token names no role.
+var AuthRoles = []string{"admin", "moderator", "user"}
+
+type Readpolicy interface {
+	Filter(db *gorm.DB, viewer Viewer, table string) *gorm.DB
+	CanRead(viewer Viewer, element Contentelement) bool
+}
+
+type Ownerpolicy struct{}
+
+type Writerule struct {
+	Owner     bool `json:"owner"`
+	Admin     bool `json:"admin"`
+	Moderator bool `json:"moderator"`
+}
+
+type Writepolicy map[string]Writerule
+
+var ReadPolicy Readpolicy
+
+var WritePolicy = Writepolicy{
+	"element.update":     {Owner: true, Admin: true},
+	"element.delete":     {Owner: true, Admin: true},
+	"element.publish":    {Admin: true},
+	"comment.update":     {Owner: true, Admin: true, Moderator: true},
+	"comment.delete":     {Owner: true, Admin: true, Moderator: true},
+	"comment.purge":      {Admin: true},
+	"comment.moderate":   {Admin: true, Moderator: true},
+	"element.transition": {Owner: true, Admin: true},
+	"element.translate":  {Owner: true, Admin: true},
+	"element.acl":        {Admin: true},
+}
+
+func (Ownerpolicy) Filter(db *gorm.DB, viewer Viewer, table string) *gorm.DB {
+	if viewer.Admin {
+		return db
+	}
+
+	now := Now()
+
+	if viewer.ID != 0 {
+		return db.Where(
+			"("+table+".status = ? AND ("+table+".publish_at IS NULL OR "+table+".publish_at <= ?) AND ("+table+".unpublish_at IS NULL OR "+table+".unpublish_at > ?)) OR "+table+".user_id = ?",
+			"active", now, now, viewer.ID,
+		)
+	}
+
+	return public(db, table, now)
+}
+
+func (Ownerpolicy) CanRead(viewer Viewer, element Contentelement) bool {
+	return viewer.Admin || (viewer.ID != 0 && element.UserID == viewer.ID) || element.isPublic(Now())
+}
+
+func (p Writepolicy) Can(viewer Viewer, action string, ownerID int) bool {
+	rule, ok := p[action]
+	if !ok {
+		return false
+	}
+
+	return rule.Admin && viewer.Admin ||
+		rule.Moderator && viewer.Moderator ||
+		rule.Owner && viewer.ID != 0 && viewer.ID == ownerID
+}
+
+func newViewer(id int, roles []string) Viewer {
+	return Viewer{
+		ID:        id,
+		Roles:     roles,
+		Admin:     hasState("admin", roles),
+		Moderator: hasState("moderator", roles),
+	}
+}
+
+func bearer(r *http.Request) string {
+	return strings.TrimSpace(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer"))
+}
+
+func (Tokenroles) Roles(r *http.Request) []string {
+	var claims struct {
+		Role  string   `json:"role"`
+		Roles []string `json:"roles"`
+	}
+
+	parts := strings.Split(bearer(r), ".")
+
+	if len(parts) != 3 {
+		return nil
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+
+	if err != nil || json.Unmarshal(payload, &claims) != nil {
+		return nil
+	}
+
+	if claims.Role != "" {
+		claims.Roles = append(claims.Roles, claims.Role)
+	}
+
+	return claims.Roles
+}
+
+func withViewer(r *http.Request, viewer Viewer) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), viewerKey{}, viewer))
+}
+
+func viewerOf(r *http.Request) Viewer {
+	viewer, _ := r.Context().Value(viewerKey{}).(Viewer)
+
+	return viewer
+}
+
+// protect wraps App.Protect and resolves the viewer once per request. When
+// the token carries no roles the viewer gets the route role, or "user" if
+// the route accepts several.
+func protect(next http.HandlerFunc, roles []string) http.HandlerFunc {
+	return App.Protect(func(w http.ResponseWriter, r *http.Request) {
+		id, _ := strconv.Atoi(r.Header.Get("id"))
+		granted := RoleResolver.Roles(r)
+
+		if len(granted) == 0 {
+			granted = []string{"user"}
+
+			if len(roles) == 1 {
+				granted = roles
+			}
+		}
+
+		next(w, withViewer(r, newViewer(id, granted)))
+	}, roles)
+}
+
+// aclActions can also be granted by a matching write entry of the element
+// or one of its ancestors.
+var aclActions = map[string]bool{
+	"element.update": true,
+	"element.delete": true,
+}
+
+func canChange(r *http.Request, action string, element Contentelement) bool {
+	viewer := viewerOf(r)
+
+	if !allowed(viewer, element, "write") {
+		return false
+	}
+
+	return WritePolicy.Can(viewer, action, element.UserID) || aclActions[action] && grants(viewer, element, "write")
+}
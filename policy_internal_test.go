@@ -0,0 +1,242 @@
+package contentelements
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-rest-framework/core"
+	"github.com/gorilla/mux"
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+)
+
+func testApp(t *testing.T) func() {
+	db, err := gorm.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db.DB().SetMaxOpenConns(1)
+	db.AutoMigrate(&Contentelement{}, &Contentcomment{}, &Contenttag{}, &Contentelementrevision{}, &Contentredirect{}, &Contenttype{}, &Contentfieldvalue{}, &Contentelementtranslation{}, &Contenttransition{}, &Contentelementdraft{}, &Contentacl{}, &Contentreaction{})
+
+	saved, savedIndex := App, Index
+
+	App = core.App{DB: db, IsTest: true}
+	Index = NewMemoryindex()
+
+	if ReadPolicy == nil {
+		ReadPolicy = Ownerpolicy{}
+	}
+
+	return func() {
+		db.Close()
+		App, Index = saved, savedIndex
+	}
+}
+
+func asViewer(method, url, body string, vars map[string]string, viewer Viewer) *http.Request {
+	r := httptest.NewRequest(method, url, strings.NewReader(body))
+	r.Header.Set("id", fmt.Sprint(viewer.ID))
+
+	return withViewer(mux.SetURLVars(r, vars), viewer)
+}
+
+func TestUpdateByAdminNotOwner(t *testing.T) {
+	defer testApp(t)()
+
+	var (
+		owner = newViewer(7, []string{"user"})
+		admin = newViewer(10, []string{"admin"})
+	)
+
+	element := Contentelement{Urld: "policy", Title: "Before", Kind: "standart", Status: "draft", UserID: owner.ID}
+	App.DB.Create(&element)
+
+	vars := map[string]string{"id": fmt.Sprint(element.ID)}
+	body := `{"urld":"policy","title":"After","kind":"standart","status":"draft"}`
+
+	actionUpdate(httptest.NewRecorder(), asViewer("PATCH", "/contentelements/1", body, vars, admin))
+
+	App.DB.First(&element, element.ID)
+
+	if element.Title != "After" {
+		t.Errorf("Admin who is not owner can't update element with default policy")
+	}
+
+	saved := WritePolicy
+	WritePolicy = Writepolicy{"element.update": {Owner: true}}
+	defer func() { WritePolicy = saved }()
+
+	body = `{"urld":"policy","title":"Again","kind":"standart","status":"draft"}`
+
+	actionUpdate(httptest.NewRecorder(), asViewer("PATCH", "/contentelements/1", body, vars, admin))
+
+	App.DB.First(&element, element.ID)
+
+	if element.Title != "After" {
+		t.Errorf("Admin who is not owner updated element with owner only policy")
+	}
+
+	actionUpdate(httptest.NewRecorder(), asViewer("PATCH", "/contentelements/1", body, vars, owner))
+
+	App.DB.First(&element, element.ID)
+
+	if element.Title != "Again" {
+		t.Errorf("Owner can't update element with owner only policy")
+	}
+}
+
+func TestDeleteCommentByModerator(t *testing.T) {
+	defer testApp(t)()
+
+	var (
+		moderator = newViewer(9, []string{"moderator"})
+		stranger  = newViewer(8, []string{"user"})
+		count     int
+	)
+
+	element := Contentelement{Urld: "comments", Title: "Comments", Kind: "standart", Status: "active", UserID: 10}
+	App.DB.Create(&element)
+
+	comment := Contentcomment{Comment: "Mine", UserID: 7, ContentelementID: int(element.ID), Status: "approved"}
+	App.DB.Create(&comment)
+
+	vars := map[string]string{"id": fmt.Sprint(element.ID), "cid": fmt.Sprint(comment.ID)}
+
+	actionDeleteComment(httptest.NewRecorder(), asViewer("DELETE", "/contentelements/1/comments/1", "", vars, stranger))
+
+	App.DB.Model(&Contentcomment{}).Where("id = ?", comment.ID).Count(&count)
+
+	if count != 1 {
+		t.Errorf("User deleted comment of another user")
+	}
+
+	actionDeleteComment(httptest.NewRecorder(), asViewer("DELETE", "/contentelements/1/comments/1", "", vars, moderator))
+
+	App.DB.Model(&Contentcomment{}).Where("id = ?", comment.ID).Count(&count)
+
+	if count != 0 {
+		t.Errorf("Moderator can't delete comment of another user")
+	}
+}
+
+func TestModeratedByOwner(t *testing.T) {
+	defer testApp(t)()
+
+	var (
+		owner = newViewer(7, []string{"user"})
+		admin = newViewer(10, []string{"admin"})
+		on    = true
+	)
+
+	element := Contentelement{Urld: "moderated", Title: "Moderated", Kind: "standart", Status: "active", UserID: owner.ID, Moderated: &on}
+	App.DB.Create(&element)
+
+	vars := map[string]string{"id": fmt.Sprint(element.ID)}
+	body := `{"urld":"moderated","title":"Moderated","kind":"standart","status":"active","moderated":false}`
+
+	actionUpdate(httptest.NewRecorder(), asViewer("PATCH", "/contentelements/1", body, vars, owner))
+
+	App.DB.First(&element, element.ID)
+
+	if element.Moderated == nil || !*element.Moderated {
+		t.Errorf("Owner turned off comment premoderation")
+	}
+
+	body = `{"urld":"moderated","title":"Moderated","kind":"standart","status":"active","moderated":true}`
+	rec := httptest.NewRecorder()
+
+	actionUpdate(rec, asViewer("PATCH", "/contentelements/1", body, vars, owner))
+
+	if strings.Contains(rec.Body.String(), "Only moderators") {
+		t.Errorf("Unchanged moderation flag is rejected for owner")
+	}
+
+	body = `{"urld":"moderated","title":"Moderated","kind":"standart","status":"active","moderated":false}`
+
+	actionUpdate(httptest.NewRecorder(), asViewer("PATCH", "/contentelements/1", body, vars, admin))
+
+	App.DB.First(&element, element.ID)
+
+	if element.Moderated == nil || *element.Moderated {
+		t.Errorf("Admin can't turn off comment premoderation")
+	}
+}
+
+func TestPublishDraftByOwner(t *testing.T) {
+	defer testApp(t)()
+
+	var (
+		owner = newViewer(7, []string{"user"})
+		admin = newViewer(10, []string{"admin"})
+	)
+
+	element := Contentelement{Urld: "owned", Title: "Live", Kind: "standart", Status: "active", UserID: owner.ID}
+	App.DB.Create(&element)
+
+	vars := map[string]string{"id": fmt.Sprint(element.ID)}
+	body := `{"urld":"owned","title":"Edited","kind":"standart","status":"active"}`
+
+	actionUpdate(httptest.NewRecorder(), asViewer("PATCH", "/contentelements/1", body, vars, owner))
+
+	if findDraft(element.ID).ID == 0 {
+		t.Fatal("Owner edit of active element is not saved as draft")
+	}
+
+	actionPublishDraft(httptest.NewRecorder(), asViewer("POST", "/contentelements/1/draft/publish", "", vars, owner))
+
+	App.DB.First(&element, element.ID)
+
+	if element.Title != "Live" {
+		t.Errorf("Owner published draft without approval")
+	}
+
+	actionPublishDraft(httptest.NewRecorder(), asViewer("POST", "/contentelements/1/draft/publish", "", vars, admin))
+
+	App.DB.First(&element, element.ID)
+
+	if element.Title != "Edited" {
+		t.Errorf("Admin can't publish draft: %s", element.Title)
+	}
+}
+
+func TestWriteAclGrants(t *testing.T) {
+	defer testApp(t)()
+
+	var (
+		owner    = newViewer(7, []string{"user"})
+		partner  = newViewer(20, []string{"partner"})
+		stranger = newViewer(8, []string{"user"})
+	)
+
+	element := Contentelement{Urld: "shared", Title: "Shared", Kind: "standart", Status: "draft", UserID: owner.ID}
+	App.DB.Create(&element)
+	App.DB.Create(&Contentacl{ContentelementID: int(element.ID), Role: "partner", Permission: "write"})
+
+	vars := map[string]string{"id": fmt.Sprint(element.ID)}
+
+	cases := []struct {
+		viewer Viewer
+		title  string
+		want   string
+	}{
+		{stranger, "By stranger", "Shared"},
+		{partner, "By partner", "By partner"},
+		{owner, "By owner", "By partner"},
+	}
+
+	for _, c := range cases {
+		body := fmt.Sprintf(`{"urld":"shared","title":"%s","kind":"standart","status":"draft"}`, c.title)
+
+		actionUpdate(httptest.NewRecorder(), asViewer("PATCH", "/contentelements/1", body, vars, c.viewer))
+
+		App.DB.First(&element, element.ID)
+
+		if element.Title != c.want {
+			t.Errorf("Wrong title after update by %v: %s, need %s", c.viewer.Roles, element.Title, c.want)
+		}
+	}
+}
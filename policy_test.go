@@ -0,0 +1,181 @@
+package contentelements_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-rest-framework/contentelements"
+	"github.com/icrowley/fake"
+)
+
+func TestReadPolicy(t *testing.T) {
+	el := &contentelements.Contentelement{
+		Title:  fake.Title(),
+		Kind:   "standart",
+		Status: "suspend",
+	}
+
+	uj, err := json.Marshal(el)
+	if err != nil {
+		fmt.Printf("Error: %s", err)
+		return
+	}
+
+	resp := doRequest(Murl, "POST", string(uj), AdminToken)
+
+	if resp.StatusCode != 200 {
+		t.Errorf("Success expected: %d", resp.StatusCode)
+	}
+
+	u := readElementBody(resp, t)
+
+	if len(u.Errors) != 0 {
+		t.Fatal(u.Errors)
+	}
+
+	id := u.Data.ID
+	url := fmt.Sprintf("%s/%d", Murl, id)
+	utitle, _ := toUrlcode(el.Title)
+
+	resp = doRequest(url, "GET", "", UserToken)
+
+	u = readElementBody(resp, t)
+
+	if len(u.Errors) == 0 {
+		t.Errorf("Suspended element is visible to user who is not owner")
+	}
+
+	resp = doRequest(Murl+"?status=suspend&title="+utitle, "GET", "", UserToken)
+
+	list := readElementsBody(resp, t)
+
+	if len(list.Data) != 0 {
+		t.Errorf("Suspended element is listed for user who is not owner")
+	}
+
+	resp = doRequest(url, "GET", "", AdminToken)
+
+	u = readElementBody(resp, t)
+
+	if len(u.Errors) != 0 {
+		t.Fatal(u.Errors)
+	}
+
+	resp = doRequest(Murl+"?status=suspend&title="+utitle, "GET", "", AdminToken)
+
+	list = readElementsBody(resp, t)
+
+	if len(list.Data) != 1 {
+		t.Errorf("Suspended element is not listed for admin: %d", len(list.Data))
+	}
+
+	deleteElement(t, id)
+
+	return
+}
+
+func TestWritePolicy(t *testing.T) {
+	var (
+		anonymous = contentelements.Viewer{}
+		owner     = contentelements.Viewer{ID: 7}
+		stranger  = contentelements.Viewer{ID: 8}
+		moderator = contentelements.Viewer{ID: 9, Moderator: true}
+		admin     = contentelements.Viewer{ID: 10, Admin: true}
+	)
+
+	cases := []struct {
+		viewer contentelements.Viewer
+		action string
+		can    bool
+	}{
+		{anonymous, "element.update", false},
+		{owner, "element.update", true},
+		{stranger, "element.update", false},
+		{moderator, "element.update", false},
+		{admin, "element.update", true},
+		{anonymous, "element.delete", false},
+		{owner, "element.delete", true},
+		{stranger, "element.delete", false},
+		{moderator, "element.delete", false},
+		{admin, "element.delete", true},
+		{anonymous, "comment.update", false},
+		{owner, "comment.update", true},
+		{stranger, "comment.update", false},
+		{moderator, "comment.update", true},
+		{admin, "comment.update", true},
+		{anonymous, "comment.delete", false},
+		{owner, "comment.delete", true},
+		{stranger, "comment.delete", false},
+		{moderator, "comment.delete", true},
+		{admin, "comment.delete", true},
+		{owner, "element.publish", false},
+		{moderator, "element.publish", false},
+		{admin, "element.publish", true},
+		{owner, "comment.purge", false},
+		{moderator, "comment.purge", false},
+		{admin, "comment.purge", true},
+		{owner, "comment.moderate", false},
+		{moderator, "comment.moderate", true},
+		{admin, "comment.moderate", true},
+		{owner, "element.translate", true},
+		{stranger, "element.translate", false},
+		{admin, "element.translate", true},
+		{owner, "element.acl", false},
+		{admin, "element.acl", true},
+		{admin, "unknown.action", false},
+	}
+
+	for _, c := range cases {
+		if got := contentelements.WritePolicy.Can(c.viewer, c.action, owner.ID); got != c.can {
+			t.Errorf("Wrong decision for %+v on %s: %v, need %v", c.viewer, c.action, got, c.can)
+		}
+	}
+
+	policy := contentelements.Writepolicy{
+		"element.update": {Owner: true},
+	}
+
+	if policy.Can(admin, "element.update", owner.ID) {
+		t.Errorf("Admin override is not disabled by configured rule")
+	}
+
+	if !policy.Can(owner, "element.update", owner.ID) {
+		t.Errorf("Owner is rejected by configured rule")
+	}
+
+	return
+}
+
+func TestTokenroles(t *testing.T) {
+	cases := []struct {
+		claims string
+		roles  int
+	}{
+		{`{"id":7,"role":"moderator"}`, 1},
+		{`{"id":7,"roles":["user","partner"]}`, 2},
+		{`{"id":7}`, 0},
+	}
+
+	for _, c := range cases {
+		token := "e30." + base64.RawURLEncoding.EncodeToString([]byte(c.claims)) + ".sig"
+
+		r := httptest.NewRequest("GET", Murl, nil)
+		r.Header.Set("Authorization", "Bearer "+token)
+
+		if roles := (contentelements.Tokenroles{}).Roles(r); len(roles) != c.roles {
+			t.Errorf("Wrong roles for %s: %v", c.claims, roles)
+		}
+	}
+
+	r := httptest.NewRequest("GET", Murl, nil)
+	r.Header.Set("Authorization", "Bearer broken")
+
+	if roles := (contentelements.Tokenroles{}).Roles(r); len(roles) != 0 {
+		t.Errorf("Roles read from broken token: %v", roles)
+	}
+
+	return
+}
@@ -0,0 +1,90 @@
+package contentelements
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-rest-framework/core"
+	"github.com/gorilla/mux"
+)
+
+var (
+	PreviewSecret []byte
+	PreviewTTL    = time.Hour
+	PreviewMaxTTL = 7 * 24 * time.Hour
+)
+
+type Contentpreview struct {
+	Token   string    `json:"token"`
+	Expires time.Time `json:"expires"`
+}
+
+func initPreviewSecret() {
+	if len(PreviewSecret) != 0 {
+		return
+	}
+
+	PreviewSecret = make([]byte, 32)
+	rand.Read(PreviewSecret)
+}
+
+func previewToken(id uint, expires time.Time) string {
+	mac := hmac.New(sha256.New, PreviewSecret)
+	fmt.Fprintf(mac, "%d:%d", id, expires.Unix())
+
+	return fmt.Sprintf("%d.%s", expires.Unix(), hex.EncodeToString(mac.Sum(nil)))
+}
+
+func checkPreview(id uint, token string) bool {
+	i := strings.Index(token, ".")
+	if i <= 0 {
+		return false
+	}
+
+	expires, err := strconv.ParseInt(token[:i], 10, 64)
+	if err != nil || Now().Unix() > expires {
+		return false
+	}
+
+	return hmac.Equal([]byte(token), []byte(previewToken(id, time.Unix(expires, 0))))
+}
+
+func actionPreviewToken(w http.ResponseWriter, r *http.Request) {
+	var (
+		element Contentelement
+		preview Contentpreview
+		rsp     = core.Response{Data: &preview, Req: r}
+		vars    = mux.Vars(r)
+		ttl     = PreviewTTL
+	)
+
+	if s := r.FormValue("ttl"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 || time.Duration(n)*time.Second > PreviewMaxTTL {
+			rsp.Errors.Add("ttl", "Wrong preview lifetime")
+			w.Write(rsp.Make())
+			return
+		}
+		ttl = time.Duration(n) * time.Second
+	}
+
+	App.DB.Select("id").First(&element, vars["id"])
+
+	if element.ID == 0 {
+		rsp.Errors.Add("ID", "Contentelement not found")
+	} else {
+		preview.Expires = Now().Add(ttl).Truncate(time.Second)
+		preview.Token = previewToken(element.ID, preview.Expires)
+	}
+
+	rsp.Data = &preview
+
+	w.Write(rsp.Make())
+}
@@ -0,0 +1,94 @@
+package contentelements_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/go-rest-framework/contentelements"
+	"github.com/icrowley/fake"
+)
+
+func TestPreview(t *testing.T) {
+	el := &contentelements.Contentelement{
+		Title:  fake.Title(),
+		Kind:   "standart",
+		Status: "draft",
+	}
+
+	uj, err := json.Marshal(el)
+	if err != nil {
+		fmt.Printf("Error: %s", err)
+		return
+	}
+
+	resp := doRequest(Murl, "POST", string(uj), AdminToken)
+
+	if resp.StatusCode != 200 {
+		t.Errorf("Success expected: %d", resp.StatusCode)
+	}
+
+	u := readElementBody(resp, t)
+
+	if len(u.Errors) != 0 {
+		t.Fatal(u.Errors)
+	}
+
+	id := u.Data.ID
+	url := fmt.Sprintf("%s/%d", Murl, id)
+
+	resp = doRequest(url, "GET", "", "")
+
+	u = readElementBody(resp, t)
+
+	if len(u.Errors) == 0 {
+		t.Errorf("Draft element is visible to anonymous reader")
+	}
+
+	utitle, _ := toUrlcode(el.Title)
+	list := GetOne(t, Murl+"?status=draft&title="+utitle)
+
+	if len(list.Data) != 0 {
+		t.Errorf("Draft element is listed for anonymous reader")
+	}
+
+	resp = doRequest(url+"/preview-token?ttl=600", "POST", "", AdminToken)
+
+	p := readPreviewBody(resp, t)
+
+	if len(p.Errors) != 0 || p.Data.Token == "" {
+		t.Fatal(p.Errors)
+	}
+
+	resp = doRequest(url+"?preview="+p.Data.Token, "GET", "", "")
+
+	u = readElementBody(resp, t)
+
+	if len(u.Errors) != 0 {
+		t.Fatal(u.Errors)
+	}
+
+	if u.Data.ID != id {
+		t.Errorf("Wrong element in preview: %d", u.Data.ID)
+	}
+
+	resp = doRequest(url+"?preview="+p.Data.Token+"0", "GET", "", "")
+
+	u = readElementBody(resp, t)
+
+	if len(u.Errors) == 0 {
+		t.Errorf("Tampered preview token is accepted")
+	}
+
+	resp = doRequest(url+"/preview-token?ttl=0", "POST", "", AdminToken)
+
+	p = readPreviewBody(resp, t)
+
+	if len(p.Errors) == 0 {
+		t.Errorf("Wrong preview lifetime is accepted")
+	}
+
+	deleteElement(t, id)
+
+	return
+}
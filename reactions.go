@@ -0,0 +1,173 @@
+package contentelements
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-rest-framework/core"
+	"github.com/gorilla/mux"
+	"github.com/jinzhu/gorm"
+)
+
+var ReactionTypes = []string{"like", "upvote"}
+
+type Contentreactioncounts map[string]int
+
+type Contentreactions []Contentreaction
+
+type Contentreaction struct {
+	gorm.Model
+	UserID     int    `json:"userID" gorm:"unique_index:idx_contentreactions_target"`
+	TargetType string `json:"target_type" gorm:"unique_index:idx_contentreactions_target"`
+	TargetID   uint   `json:"target_id" gorm:"unique_index:idx_contentreactions_target"`
+	Type       string `json:"type" gorm:"unique_index:idx_contentreactions_target"`
+}
+
+func reactionType(kind string) bool {
+	for _, v := range ReactionTypes {
+		if v == kind {
+			return true
+		}
+	}
+
+	return false
+}
+
+func reactionCounts(targetType string, ids []uint) map[uint]Contentreactioncounts {
+	type row struct {
+		TargetID uint
+		Type     string
+		Count    int
+	}
+
+	var (
+		rows   []row
+		counts = map[uint]Contentreactioncounts{}
+	)
+
+	if len(ids) == 0 {
+		return counts
+	}
+
+	App.DB.Model(&Contentreaction{}).
+		Select("target_id, type, COUNT(*) AS count").
+		Where("target_type = ? AND target_id IN (?)", targetType, ids).
+		Group("target_id, type").
+		Scan(&rows)
+
+	for _, v := range rows {
+		if counts[v.TargetID] == nil {
+			counts[v.TargetID] = Contentreactioncounts{}
+		}
+		counts[v.TargetID][v.Type] = v.Count
+	}
+
+	return counts
+}
+
+func collectCommentIDs(comments []Contentcomment, ids []uint) []uint {
+	for _, v := range comments {
+		ids = append(ids, v.ID)
+		ids = collectCommentIDs(v.Comments, ids)
+	}
+
+	return ids
+}
+
+func applyCommentReactions(comments []Contentcomment, counts map[uint]Contentreactioncounts) {
+	for i := range comments {
+		comments[i].Reactions = counts[comments[i].ID]
+		applyCommentReactions(comments[i].Comments, counts)
+	}
+}
+
+func withReactions(comments []Contentcomment) []Contentcomment {
+	applyCommentReactions(comments, reactionCounts("comment", collectCommentIDs(comments, nil)))
+
+	return comments
+}
+
+func topComments(db *gorm.DB) *gorm.DB {
+	return db.Order(gorm.Expr(
+		"(SELECT COUNT(*) FROM contentreactions WHERE deleted_at IS NULL AND target_type = ? AND target_id = contentcomments.id) DESC",
+		"comment",
+	)).Order("id")
+}
+
+func reactionTarget(rsp *core.Response, r *http.Request) (string, uint) {
+	var (
+		element Contentelement
+		comment Contentcomment
+		vars    = mux.Vars(r)
+	)
+
+	App.DB.First(&element, vars["id"])
+
+	if element.ID == 0 || !readable(r, element) {
+		rsp.Errors.Add("ID", "Contentelement not found")
+		return "", 0
+	}
+
+	if vars["cid"] == "" {
+		return "element", element.ID
+	}
+
+	App.DB.Where("contentelement_id = ?", element.ID).First(&comment, vars["cid"])
+
+	if comment.ID == 0 || comment.Deleted || comment.Status != "approved" {
+		rsp.Errors.Add("ID", "Contentcomment not found")
+		return "", 0
+	}
+
+	return "comment", comment.ID
+}
+
+func actionAddReaction(w http.ResponseWriter, r *http.Request) {
+	var (
+		data     Contentreaction
+		reaction Contentreaction
+		rsp      = core.Response{Data: &data, Req: r}
+	)
+
+	if rsp.IsJsonParseDone(r.Body) {
+		if !reactionType(data.Type) {
+			rsp.Errors.Add("type", "Reaction "+data.Type+" is not allowed")
+		} else if target, id := reactionTarget(&rsp, r); id != 0 {
+			userID, _ := strconv.Atoi(r.Header.Get("id"))
+			App.DB.Where(Contentreaction{
+				UserID:     userID,
+				TargetType: target,
+				TargetID:   id,
+				Type:       data.Type,
+			}).FirstOrCreate(&reaction)
+			rsp.Count = reactionCounts(target, []uint{id})[id][reaction.Type]
+		}
+	}
+
+	rsp.Data = &reaction
+
+	w.Write(rsp.Make())
+}
+
+func actionDeleteReaction(w http.ResponseWriter, r *http.Request) {
+	var (
+		reaction Contentreaction
+		rsp      = core.Response{Data: &reaction, Req: r}
+		vars     = mux.Vars(r)
+	)
+
+	if target, id := reactionTarget(&rsp, r); id != 0 {
+		userID, _ := strconv.Atoi(r.Header.Get("id"))
+		App.DB.Where("user_id = ? AND target_type = ? AND target_id = ? AND type = ?", userID, target, id, vars["type"]).First(&reaction)
+		if reaction.ID == 0 {
+			rsp.Errors.Add("type", "Reaction not found")
+		} else {
+			App.DB.Unscoped().Delete(&reaction)
+			rsp.Count = reactionCounts(target, []uint{id})[id][reaction.Type]
+		}
+	}
+
+	rsp.Data = &reaction
+
+	w.Write(rsp.Make())
+}
@@ -0,0 +1,91 @@
+package contentelements_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/icrowley/fake"
+)
+
+func addComment(t *testing.T, id uint) TestContentcomment {
+	url := fmt.Sprintf("%s/%d/comments", Murl, id)
+
+	resp := doRequest(url, "POST", fmt.Sprintf(`{"comment":"%s"}`, fake.Sentence()), UserToken)
+
+	if resp.StatusCode != 200 {
+		t.Errorf("Success expected: %d", resp.StatusCode)
+	}
+
+	u := readCommentBody(resp, t)
+
+	if len(u.Errors) != 0 {
+		t.Fatal(u.Errors)
+	}
+
+	return u
+}
+
+func TestReactions(t *testing.T) {
+	url := fmt.Sprintf("%s/%d", Murl, NewsTwoId)
+
+	doRequest(url+"/reactions", "POST", `{"type":"like"}`, UserToken)
+	resp := doRequest(url+"/reactions", "POST", `{"type":"like"}`, UserToken)
+
+	if resp.StatusCode != 200 {
+		t.Errorf("Success expected: %d", resp.StatusCode)
+	}
+
+	resp = doRequest(url+"/reactions", "POST", `{"type":"dislike"}`, UserToken)
+
+	c := readCommentBody(resp, t)
+
+	if len(c.Errors) == 0 {
+		t.Errorf("Unknown reaction type is accepted")
+	}
+
+	el := getElement(t, NewsTwoId)
+
+	if el.Reactions["like"] != 1 {
+		t.Errorf("Wrong element like count: %d", el.Reactions["like"])
+	}
+
+	first := addComment(t, NewsTwoId)
+	second := addComment(t, NewsTwoId)
+
+	doRequest(fmt.Sprintf("%s/comments/%d/reactions", url, second.Data.ID), "POST", `{"type":"upvote"}`, UserToken)
+	doRequest(fmt.Sprintf("%s/comments/%d/reactions", url, second.Data.ID), "POST", `{"type":"upvote"}`, AdminToken)
+
+	resp = doRequest(url+"/comments?sort=top", "GET", "", "")
+
+	list := readCommentsBody(resp, t)
+
+	if len(list.Errors) != 0 {
+		t.Fatal(list.Errors)
+	}
+
+	if len(list.Data) == 0 || list.Data[0].ID != second.Data.ID || list.Data[0].Reactions["upvote"] != 2 {
+		t.Errorf("Most upvoted comment is not first")
+	}
+
+	resp = doRequest(fmt.Sprintf("%s/comments/%d/reactions/upvote", url, second.Data.ID), "DELETE", "", UserToken)
+
+	if resp.StatusCode != 200 {
+		t.Errorf("Success expected: %d", resp.StatusCode)
+	}
+
+	resp = doRequest(url+"/comments", "GET", "", "")
+
+	list = readCommentsBody(resp, t)
+
+	if c, ok := findComment(list.Data, second.Data.ID); !ok || c.Reactions["upvote"] != 1 {
+		t.Errorf("Reaction is not removed")
+	}
+
+	doRequest(url+"/reactions/like", "DELETE", "", UserToken)
+
+	for _, id := range []uint{first.Data.ID, second.Data.ID} {
+		doRequest(fmt.Sprintf("%s/comments/%d/purge", url, id), "DELETE", "", AdminToken)
+	}
+
+	return
+}
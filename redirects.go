@@ -0,0 +1,119 @@
+package contentelements
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/go-rest-framework/core"
+	"github.com/gorilla/mux"
+	"github.com/jinzhu/gorm"
+)
+
+const maxRedirectHops = 20
+
+type Contentredirect struct {
+	gorm.Model
+	Path             string `json:"path" gorm:"type:varchar(700);index"`
+	ContentelementID int    `json:"contentelementID"`
+}
+
+type Contentresolve struct {
+	Element  Contentelement `json:"element"`
+	Status   int            `json:"status"`
+	Redirect string         `json:"redirect,omitempty"`
+}
+
+func elementPath(element Contentelement) string {
+	var segments []string
+
+	for _, v := range ancestors(App.DB, element) {
+		segments = append(segments, v.Urld)
+	}
+
+	return "/" + strings.Join(append(segments, element.Urld), "/")
+}
+
+func addRedirect(from string, element Contentelement) {
+	to := elementPath(element)
+
+	if from == to {
+		return
+	}
+
+	App.DB.Unscoped().Where("path = ?", to).Delete(&Contentredirect{})
+	App.DB.Unscoped().Where("path = ?", from).Delete(&Contentredirect{})
+
+	App.DB.Create(&Contentredirect{
+		Path:             from,
+		ContentelementID: int(element.ID),
+	})
+}
+
+func resolveRedirect(path string, hops int) (Contentelement, bool) {
+	var segments = strings.Split(strings.Trim(path, "/"), "/")
+
+	if hops > maxRedirectHops {
+		return Contentelement{}, false
+	}
+
+	if element := resolvePath(path); element.ID != 0 {
+		return element, true
+	}
+
+	for i := len(segments); i > 0; i-- {
+		var (
+			redirect Contentredirect
+			element  Contentelement
+		)
+
+		App.DB.Where("path = ?", "/"+strings.Join(segments[:i], "/")).First(&redirect)
+
+		if redirect.ID == 0 {
+			continue
+		}
+
+		App.DB.First(&element, redirect.ContentelementID)
+
+		if element.ID == 0 {
+			return element, false
+		}
+
+		if i == len(segments) {
+			return element, true
+		}
+
+		return resolveRedirect(elementPath(element)+"/"+strings.Join(segments[i:], "/"), hops+1)
+	}
+
+	return Contentelement{}, false
+}
+
+func actionResolve(w http.ResponseWriter, r *http.Request) {
+	var (
+		res  Contentresolve
+		rsp  = core.Response{Data: &res, Req: r}
+		vars = mux.Vars(r)
+		path = "/" + strings.Trim(vars["path"], "/")
+	)
+
+	element, ok := resolveRedirect(path, 0)
+
+	if ok && !readable(r, element) {
+		ok = false
+	}
+
+	if !ok {
+		rsp.Errors.Add("path", "Contentelement not found")
+	} else {
+		res.Element = element
+		res.Status = http.StatusOK
+		if canonical := elementPath(element); canonical != path {
+			res.Status = http.StatusMovedPermanently
+			res.Redirect = canonical
+		}
+	}
+
+	rsp.Data = &res
+
+	w.Write(rsp.Make())
+}
@@ -0,0 +1,94 @@
+package contentelements_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"testing"
+
+	"github.com/go-rest-framework/contentelements"
+	"github.com/go-rest-framework/core"
+	"github.com/icrowley/fake"
+)
+
+type TestContentresolve struct {
+	Errors []core.ErrorMsg                `json:"errors"`
+	Data   contentelements.Contentresolve `json:"data"`
+}
+
+func readResolveBody(r *http.Response, t *testing.T) TestContentresolve {
+	var u TestContentresolve
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		log.Fatal(err)
+	}
+	json.Unmarshal([]byte(body), &u)
+	return u
+}
+
+func renameElement(t *testing.T, el *contentelements.Contentelement, urld string) {
+	el.Urld = urld
+
+	uj, err := json.Marshal(el)
+	if err != nil {
+		fmt.Printf("Error: %s", err)
+		return
+	}
+
+	resp := doRequest(fmt.Sprintf("%s/%d", Murl, el.ID), "PATCH", string(uj), AdminToken)
+
+	u := readElementBody(resp, t)
+
+	if len(u.Errors) != 0 {
+		t.Fatal(u.Errors)
+	}
+
+	publishDraft(t, el.ID)
+}
+
+func TestRedirects(t *testing.T) {
+	cat := getElement(t, CatId2)
+	id := CreateOne(t, int(CatId2), fake.Title(), fake.Word())
+	el := getElement(t, id)
+
+	firstPath := "/" + cat.Urld + "/" + el.Urld
+
+	renameElement(t, &el, fmt.Sprintf("moved-%d", id))
+	renameElement(t, &el, fmt.Sprintf("moved-again-%d", id))
+
+	resp := doRequest(Murl+"/resolve"+firstPath, "GET", "", "")
+
+	if resp.StatusCode != 200 {
+		t.Errorf("Success expected: %d", resp.StatusCode)
+	}
+
+	u := readResolveBody(resp, t)
+
+	if len(u.Errors) != 0 {
+		t.Fatal(u.Errors)
+	}
+
+	canonical := fmt.Sprintf("/%s/moved-again-%d", cat.Urld, id)
+
+	if u.Data.Status != 301 || u.Data.Redirect != canonical {
+		t.Errorf("Wrong redirect: %d %s, need 301 %s", u.Data.Status, u.Data.Redirect, canonical)
+	}
+
+	if u.Data.Element.ID != id {
+		t.Errorf("Wrong redirect element: %d, need %d", u.Data.Element.ID, id)
+	}
+
+	resp = doRequest(Murl+"/resolve"+canonical, "GET", "", "")
+
+	u = readResolveBody(resp, t)
+
+	if u.Data.Status != 200 || u.Data.Redirect != "" {
+		t.Errorf("Canonical path redirected: %d %s", u.Data.Status, u.Data.Redirect)
+	}
+
+	deleteElement(t, id)
+
+	return
+}
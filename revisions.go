@@ -0,0 +1,196 @@
+package contentelements
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-rest-framework/core"
+	"github.com/gorilla/mux"
+	"github.com/jinzhu/gorm"
+)
+
+type Contentelementrevisions []Contentelementrevision
+
+type Contentelementrevision struct {
+	gorm.Model
+	ContentelementID int           `json:"contentelementID" gorm:"index"`
+	Revision         int           `json:"revision"`
+	UserID           int           `json:"userID"`
+	Urld             string        `json:"urld"`
+	Parent           int           `json:"parent"`
+	Title            string        `json:"title"`
+	Description      string        `json:"description" gorm:"type:varchar(500)"`
+	Content          string        `json:"content" gorm:"type:text"`
+	Meta_title       string        `json:"meta_title"`
+	Meta_descr       string        `json:"meta_descr" gorm:"type:text"`
+	Kind             string        `json:"kind"`
+	Fields           Contentfields `json:"fields" gorm:"type:text"`
+	Status           string        `json:"status"`
+	Tags             string        `json:"tags"`
+}
+
+func saveRevision(element *Contentelement, userID int) Contentelementrevision {
+	var last Contentelementrevision
+
+	App.DB.Where("contentelement_id = ?", element.ID).Order("revision DESC").First(&last)
+
+	rev := Contentelementrevision{
+		ContentelementID: int(element.ID),
+		Revision:         last.Revision + 1,
+		UserID:           userID,
+		Urld:             element.Urld,
+		Parent:           element.Parent,
+		Title:            element.Title,
+		Description:      element.Description,
+		Content:          element.Content,
+		Meta_title:       element.Meta_title,
+		Meta_descr:       element.Meta_descr,
+		Kind:             element.Kind,
+		Fields:           element.Fields,
+		Status:           element.Status,
+		Tags:             element.Tags.String(),
+	}
+
+	App.DB.Create(&rev)
+
+	return rev
+}
+
+func (rev Contentelementrevision) applyTo(element *Contentelement) {
+	element.Urld = rev.Urld
+	element.Parent = rev.Parent
+	element.Title = rev.Title
+	element.Description = rev.Description
+	element.Content = rev.Content
+	element.Meta_title = rev.Meta_title
+	element.Meta_descr = rev.Meta_descr
+	element.Kind = rev.Kind
+	element.Fields = rev.Fields
+	element.Status = rev.Status
+	element.Tags = splitTags(rev.Tags)
+}
+
+func findRevision(elementID uint, rev string) Contentelementrevision {
+	var revision Contentelementrevision
+
+	n, err := strconv.Atoi(rev)
+	if err != nil {
+		return revision
+	}
+
+	App.DB.Where("contentelement_id = ? AND revision = ?", elementID, n).First(&revision)
+
+	return revision
+}
+
+func actionRevisions(w http.ResponseWriter, r *http.Request) {
+	var (
+		element   Contentelement
+		revisions Contentelementrevisions
+		count     int
+		rsp       = core.Response{Data: &revisions, Req: r}
+		limit     = r.FormValue("limit")
+		offset    = r.FormValue("offset")
+		vars      = mux.Vars(r)
+		db        = App.DB
+	)
+
+	App.DB.First(&element, vars["id"])
+
+	if element.ID == 0 {
+		rsp.Errors.Add("ID", "Contentelement not found")
+		w.Write(rsp.Make())
+		return
+	}
+
+	db = db.Where("contentelement_id = ?", element.ID)
+	db = db.Order("revision DESC")
+
+	db.Model(&Contentelementrevision{}).Count(&count)
+
+	if limit != "" {
+		db = db.Limit(limit)
+	}
+
+	if offset != "" {
+		db = db.Offset(offset)
+	}
+
+	db.Find(&revisions)
+
+	rsp.Data = &revisions
+	rsp.Count = count
+
+	w.Write(rsp.Make())
+}
+
+func actionRevision(w http.ResponseWriter, r *http.Request) {
+	var (
+		element  Contentelement
+		revision Contentelementrevision
+		rsp      = core.Response{Data: &revision, Req: r}
+		vars     = mux.Vars(r)
+	)
+
+	App.DB.First(&element, vars["id"])
+
+	if element.ID == 0 {
+		rsp.Errors.Add("ID", "Contentelement not found")
+	} else {
+		revision = findRevision(element.ID, vars["rev"])
+		if revision.ID == 0 {
+			rsp.Errors.Add("rev", "Revision not found")
+		}
+	}
+
+	rsp.Data = &revision
+
+	w.Write(rsp.Make())
+}
+
+func actionRestoreRevision(w http.ResponseWriter, r *http.Request) {
+	var (
+		element  Contentelement
+		revision Contentelementrevision
+		rsp      = core.Response{Data: &element, Req: r}
+		vars     = mux.Vars(r)
+	)
+
+	App.DB.First(&element, vars["id"])
+
+	if element.ID == 0 {
+		rsp.Errors.Add("ID", "Contentelement not found")
+	} else {
+		revision = findRevision(element.ID, vars["rev"])
+		if revision.ID == 0 {
+			rsp.Errors.Add("rev", "Revision not found")
+		} else if !canChange(r, "element.update", element) {
+			rsp.Errors.Add("ID", "Not allowed to change element")
+		} else if checkParent(&rsp, element.ID, revision.Parent) && checkUrld(&rsp, revision.Urld, revision.Parent, element.ID) {
+			userID, _ := strconv.Atoi(r.Header.Get("id"))
+			oldPath := elementPath(element)
+			status := element.Status
+			revision.applyTo(&element)
+			element.Status = status
+			if status == "active" {
+				element.Draft = true
+				saveDraft(element, userID)
+				rsp.Data = &element
+				w.Write(rsp.Make())
+				return
+			}
+			if !urldError(&rsp, App.DB.Save(&element).Error, element) {
+				updatePath(&element)
+				setTags(&element, element.Tags)
+				syncFields(element)
+				saveRevision(&element, userID)
+				addRedirect(oldPath, element)
+				indexElement(element)
+			}
+		}
+	}
+
+	rsp.Data = &element
+
+	w.Write(rsp.Make())
+}
@@ -0,0 +1,113 @@
+package contentelements_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"testing"
+
+	"github.com/go-rest-framework/contentelements"
+	"github.com/go-rest-framework/core"
+)
+
+type TestContentelementrevisions struct {
+	Errors []core.ErrorMsg                         `json:"errors"`
+	Data   contentelements.Contentelementrevisions `json:"data"`
+}
+
+type TestContentelementrevision struct {
+	Errors []core.ErrorMsg                        `json:"errors"`
+	Data   contentelements.Contentelementrevision `json:"data"`
+}
+
+func readRevisionsBody(r *http.Response, t *testing.T) TestContentelementrevisions {
+	var u TestContentelementrevisions
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		log.Fatal(err)
+	}
+	json.Unmarshal([]byte(body), &u)
+	return u
+}
+
+func readRevisionBody(r *http.Response, t *testing.T) TestContentelementrevision {
+	var u TestContentelementrevision
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		log.Fatal(err)
+	}
+	json.Unmarshal([]byte(body), &u)
+	return u
+}
+
+func TestRevisions(t *testing.T) {
+	url := fmt.Sprintf("%s/%d/revisions", Murl, CatId1)
+
+	resp := doRequest(url, "GET", "", AdminToken)
+
+	if resp.StatusCode != 200 {
+		t.Errorf("Success expected: %d", resp.StatusCode)
+	}
+
+	u := readRevisionsBody(resp, t)
+
+	if len(u.Errors) != 0 {
+		t.Fatal(u.Errors)
+	}
+
+	if len(u.Data) < 2 {
+		t.Errorf("Wrong revisions count: %d, need >= 2", len(u.Data))
+	}
+
+	resp = doRequest(url+"/1", "GET", "", AdminToken)
+
+	r := readRevisionBody(resp, t)
+
+	if len(r.Errors) != 0 {
+		t.Fatal(r.Errors)
+	}
+
+	if r.Data.Tags != OneTags {
+		t.Errorf("Wrong first revision tags: %s, need %s", r.Data.Tags, OneTags)
+	}
+
+	resp = doRequest(url+"/0", "GET", "", AdminToken)
+
+	r = readRevisionBody(resp, t)
+
+	if len(r.Errors) == 0 {
+		t.Fatal("revision not found dont work")
+	}
+
+	return
+}
+
+func TestRestoreRevision(t *testing.T) {
+	url := fmt.Sprintf("%s/%d/revisions/1/restore", Murl, CatId1)
+
+	resp := doRequest(url, "POST", "", AdminToken)
+
+	if resp.StatusCode != 200 {
+		t.Errorf("Success expected: %d", resp.StatusCode)
+	}
+
+	u := readElementBody(resp, t)
+
+	if len(u.Errors) != 0 {
+		t.Fatal(u.Errors)
+	}
+
+	if u.Data.Tags.String() != OneTags {
+		t.Errorf("Wrong restored tags: %s, need %s", u.Data.Tags, OneTags)
+	}
+
+	if !u.Data.Draft {
+		t.Errorf("Restore of active element is not saved as draft")
+	}
+
+	publishDraft(t, CatId1)
+
+	return
+}
@@ -0,0 +1,115 @@
+package contentelements
+
+import (
+	"sync"
+	"time"
+
+	"github.com/go-rest-framework/core"
+	"github.com/jinzhu/gorm"
+)
+
+var Now = time.Now
+
+var SchedulerInterval = time.Minute
+
+var (
+	schedulerMu   sync.Mutex
+	schedulerStop chan struct{}
+)
+
+func startScheduler() {
+	StopScheduler()
+
+	schedulerMu.Lock()
+	defer schedulerMu.Unlock()
+
+	schedulerStop = make(chan struct{})
+	go scheduler(schedulerStop)
+}
+
+func StopScheduler() {
+	schedulerMu.Lock()
+	defer schedulerMu.Unlock()
+
+	if schedulerStop != nil {
+		close(schedulerStop)
+		schedulerStop = nil
+	}
+}
+
+func scheduler(stop <-chan struct{}) {
+	ticker := time.NewTicker(SchedulerInterval)
+	defer ticker.Stop()
+
+	for {
+		RunSchedule(Now())
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunSchedule publishes approved elements whose publish_at has passed,
+// together with their pending draft, and suspends expired ones. Drafts and
+// items in review are never published by the scheduler.
+func RunSchedule(now time.Time) {
+	var due, expired Contentelements
+
+	App.DB.Where("status IN (?) AND publish_at <= ?", []string{"active", "suspend"}, now).
+		Where("unpublish_at IS NULL OR unpublish_at > ?", now).
+		Find(&due)
+
+	for _, element := range due {
+		App.DB.Model(&element).UpdateColumn("publish_at", nil)
+		element.PublishAt = nil
+
+		if draft := findDraft(element.ID); draft.ID != 0 {
+			publishDraft(&core.Response{}, &element, draft, 0)
+		}
+
+		if element.Status == "suspend" {
+			applyTransition(&element, Workflowtransition{Name: "schedule", To: "active"}, 0, "")
+		}
+	}
+
+	App.DB.Where("unpublish_at <= ?", now).Find(&expired)
+
+	for _, element := range expired {
+		App.DB.Model(&element).UpdateColumns(map[string]interface{}{"publish_at": nil, "unpublish_at": nil})
+		element.PublishAt, element.UnpublishAt = nil, nil
+
+		if element.Status == "active" {
+			applyTransition(&element, Workflowtransition{Name: "schedule", To: "suspend"}, 0, "")
+		}
+	}
+}
+
+func (e Contentelement) isScheduled(now time.Time) bool {
+	if e.PublishAt != nil && e.PublishAt.After(now) {
+		return false
+	}
+
+	if e.UnpublishAt != nil && !e.UnpublishAt.After(now) {
+		return false
+	}
+
+	return true
+}
+
+func (e Contentelement) isPublic(now time.Time) bool {
+	return e.Status == "active" && e.isScheduled(now)
+}
+
+func public(db *gorm.DB, table string, now time.Time) *gorm.DB {
+	return scheduled(db.Where(table+".status = ?", "active"), table, now)
+}
+
+func scheduled(db *gorm.DB, table string, now time.Time) *gorm.DB {
+	db = db.Where(table+".publish_at IS NULL OR "+table+".publish_at <= ?", now)
+	db = db.Where(table+".unpublish_at IS NULL OR "+table+".unpublish_at > ?", now)
+
+	return db
+}
@@ -0,0 +1,111 @@
+package contentelements
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRunSchedule(t *testing.T) {
+	defer testApp(t)()
+
+	var (
+		now    = time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+		past   = now.Add(-time.Hour)
+		future = now.Add(time.Hour)
+		saved  = Now
+	)
+
+	Now = func() time.Time { return now }
+	defer func() { Now = saved }()
+
+	approved := Contentelement{Urld: "approved", Title: "Approved", Status: "active", PublishAt: &past}
+	suspended := Contentelement{Urld: "suspended", Title: "Suspended", Status: "suspend", PublishAt: &past}
+	draft := Contentelement{Urld: "draft", Title: "Draft", Status: "draft", PublishAt: &past}
+	later := Contentelement{Urld: "later", Title: "Later", Status: "suspend", PublishAt: &future}
+	expired := Contentelement{Urld: "expired", Title: "Expired", Status: "active", UnpublishAt: &past}
+
+	for _, e := range []*Contentelement{&approved, &suspended, &draft, &later, &expired} {
+		App.DB.Create(e)
+	}
+
+	saveDraft(Contentelement{Model: approved.Model, Urld: "approved", Title: "Scheduled change"}, 7)
+
+	RunSchedule(Now())
+
+	cases := []struct {
+		element Contentelement
+		status  string
+		audit   int
+	}{
+		{approved, "active", 0},
+		{suspended, "active", 1},
+		{draft, "draft", 0},
+		{later, "suspend", 0},
+		{expired, "suspend", 1},
+	}
+
+	for _, c := range cases {
+		var (
+			element Contentelement
+			audit   Contenttransitions
+		)
+
+		App.DB.First(&element, c.element.ID)
+		App.DB.Where("contentelement_id = ? AND transition = ? AND user_id = 0", c.element.ID, "schedule").Find(&audit)
+
+		if element.Status != c.status {
+			t.Errorf("Wrong status of %s after schedule: %s, need %s", element.Urld, element.Status, c.status)
+		}
+
+		if len(audit) != c.audit {
+			t.Errorf("Wrong scheduler audit of %s: %d rows, need %d", element.Urld, len(audit), c.audit)
+		}
+	}
+
+	App.DB.First(&approved, approved.ID)
+
+	if approved.Title != "Scheduled change" || approved.PublishAt != nil {
+		t.Errorf("Pending draft is not published on schedule: %s", approved.Title)
+	}
+
+	if findDraft(approved.ID).ID != 0 {
+		t.Errorf("Draft is not removed after scheduled publish")
+	}
+
+	if latestRevision(suspended.ID) == 0 || latestRevision(expired.ID) == 0 {
+		t.Errorf("Revision is not saved for scheduled transition")
+	}
+}
+
+func TestScheduleActiveElement(t *testing.T) {
+	defer testApp(t)()
+
+	var (
+		admin   = newViewer(10, []string{"admin"})
+		element = Contentelement{Urld: "live", Title: "Live", Kind: "standart", Status: "active"}
+		vars    map[string]string
+	)
+
+	App.DB.Create(&element)
+
+	vars = map[string]string{"id": fmt.Sprint(element.ID)}
+	body := `{"urld":"live","title":"Live","kind":"standart","status":"active","unpublish_at":"2030-01-01T12:00:00Z"}`
+
+	actionUpdate(httptest.NewRecorder(), asViewer("PATCH", "/contentelements/1", body, vars, admin))
+
+	App.DB.First(&element, element.ID)
+
+	if element.UnpublishAt == nil {
+		t.Fatal("Unpublish date is not saved for active element")
+	}
+
+	RunSchedule(time.Date(2030, 1, 1, 13, 0, 0, 0, time.UTC))
+
+	App.DB.First(&element, element.ID)
+
+	if element.Status != "suspend" {
+		t.Errorf("Active element is not unpublished on schedule: %s", element.Status)
+	}
+}
@@ -0,0 +1,62 @@
+package contentelements_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-rest-framework/contentelements"
+	"github.com/icrowley/fake"
+)
+
+func TestScheduledPublishing(t *testing.T) {
+	publishAt := time.Now().Add(time.Hour)
+
+	el := &contentelements.Contentelement{
+		Title:     fake.Title(),
+		Kind:      "standart",
+		Status:    "active",
+		PublishAt: &publishAt,
+	}
+
+	uj, err := json.Marshal(el)
+	if err != nil {
+		fmt.Printf("Error: %s", err)
+		return
+	}
+
+	resp := doRequest(Murl, "POST", string(uj), AdminToken)
+
+	if resp.StatusCode != 200 {
+		t.Errorf("Success expected: %d", resp.StatusCode)
+	}
+
+	u := readElementBody(resp, t)
+
+	if len(u.Errors) != 0 {
+		t.Fatal(u.Errors)
+	}
+
+	url := fmt.Sprintf("%s/%d", Murl, u.Data.ID)
+
+	resp = doRequest(url, "GET", "", "")
+
+	u1 := readElementBody(resp, t)
+
+	if len(u1.Errors) == 0 {
+		t.Errorf("Element scheduled for publishing is visible to anonymous reader")
+	}
+
+	resp = doRequest(url, "GET", "", AdminToken)
+
+	u1 = readElementBody(resp, t)
+
+	if len(u1.Errors) != 0 {
+		t.Fatal(u1.Errors)
+	}
+
+	deleteElement(t, u.Data.ID)
+
+	return
+}
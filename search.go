@@ -0,0 +1,435 @@
+package contentelements
+
+import (
+	"html"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/go-rest-framework/core"
+)
+
+type Searchindex interface {
+	Add(element Contentelement)
+	Remove(id uint)
+	Search(query string) []Searchhit
+}
+
+type Searchhit struct {
+	ID      uint    `json:"id"`
+	Score   float64 `json:"score"`
+	Snippet string  `json:"snippet"`
+}
+
+type Searchresult struct {
+	Element Contentelement `json:"element"`
+	Score   float64        `json:"score"`
+	Snippet string         `json:"snippet"`
+}
+
+type Searchresults []Searchresult
+
+var Index Searchindex
+
+var searchFields = map[string]float64{
+	"title":       3,
+	"tags":        2.5,
+	"description": 1.5,
+	"content":     1,
+}
+
+type searchPosting struct {
+	field string
+	pos   int
+}
+
+type searchDoc struct {
+	text   map[string]string
+	length int
+}
+
+type Memoryindex struct {
+	mu       sync.RWMutex
+	docs     map[uint]searchDoc
+	postings map[string]map[uint][]searchPosting
+}
+
+func NewMemoryindex() *Memoryindex {
+	return &Memoryindex{
+		docs:     map[uint]searchDoc{},
+		postings: map[string]map[uint][]searchPosting{},
+	}
+}
+
+func tokenize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func (m *Memoryindex) Add(element Contentelement) {
+	m.Remove(element.ID)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	doc := searchDoc{text: map[string]string{
+		"title":       element.Title,
+		"tags":        strings.Join(element.Tags, " "),
+		"description": element.Description,
+		"content":     element.Content,
+	}}
+
+	for field, text := range doc.text {
+		for pos, term := range tokenize(text) {
+			if m.postings[term] == nil {
+				m.postings[term] = map[uint][]searchPosting{}
+			}
+			m.postings[term][element.ID] = append(m.postings[term][element.ID], searchPosting{field, pos})
+			doc.length++
+		}
+	}
+
+	m.docs[element.ID] = doc
+}
+
+func (m *Memoryindex) Remove(id uint) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.docs[id]; !ok {
+		return
+	}
+
+	for term, docs := range m.postings {
+		delete(docs, id)
+		if len(docs) == 0 {
+			delete(m.postings, term)
+		}
+	}
+
+	delete(m.docs, id)
+}
+
+func (m *Memoryindex) Search(query string) []Searchhit {
+	var (
+		hits   []Searchhit
+		scores map[uint]float64
+		terms  []string
+	)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, clause := range parseQuery(query) {
+		matched := m.matchClause(clause)
+
+		if scores == nil {
+			scores = matched
+		} else {
+			for id := range scores {
+				if _, ok := matched[id]; !ok {
+					delete(scores, id)
+				} else {
+					scores[id] += matched[id]
+				}
+			}
+		}
+
+		terms = append(terms, clause...)
+	}
+
+	for id, score := range scores {
+		hits = append(hits, Searchhit{
+			ID:      id,
+			Score:   score,
+			Snippet: snippet(m.docs[id], terms),
+		})
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score == hits[j].Score {
+			return hits[i].ID > hits[j].ID
+		}
+		return hits[i].Score > hits[j].Score
+	})
+
+	return hits
+}
+
+func (m *Memoryindex) expand(term string) []string {
+	if !strings.HasSuffix(term, "*") {
+		return []string{term}
+	}
+
+	var (
+		prefix = strings.TrimSuffix(term, "*")
+		res    []string
+	)
+
+	for t := range m.postings {
+		if strings.HasPrefix(t, prefix) {
+			res = append(res, t)
+		}
+	}
+
+	return res
+}
+
+func (m *Memoryindex) idf(term string) float64 {
+	return math.Log(1 + float64(len(m.docs))/float64(1+len(m.postings[term])))
+}
+
+func (m *Memoryindex) matchClause(clause []string) map[uint]float64 {
+	var res = map[uint]float64{}
+
+	if len(clause) == 1 {
+		for _, term := range m.expand(clause[0]) {
+			idf := m.idf(term)
+			for id, postings := range m.postings[term] {
+				for _, p := range postings {
+					res[id] += searchFields[p.field] * idf / math.Sqrt(float64(m.docs[id].length))
+				}
+			}
+		}
+		return res
+	}
+
+	for id, first := range m.postings[clause[0]] {
+		for _, p := range first {
+			if m.phraseAt(id, p, clause[1:]) {
+				res[id] += searchFields[p.field] * float64(len(clause)) * m.idf(clause[0])
+			}
+		}
+	}
+
+	return res
+}
+
+func (m *Memoryindex) phraseAt(id uint, start searchPosting, rest []string) bool {
+	for i, term := range rest {
+		found := false
+		for _, p := range m.postings[term][id] {
+			if p.field == start.field && p.pos == start.pos+i+1 {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+func parseQuery(q string) [][]string {
+	var (
+		res    [][]string
+		phrase = false
+	)
+
+	for _, part := range strings.Split(q, "\"") {
+		if phrase {
+			if terms := tokenize(part); len(terms) != 0 {
+				res = append(res, terms)
+			}
+		} else {
+			for _, word := range strings.Fields(part) {
+				prefix := strings.HasSuffix(word, "*")
+				for _, term := range tokenize(word) {
+					if prefix {
+						term += "*"
+					}
+					res = append(res, []string{term})
+				}
+			}
+		}
+		phrase = !phrase
+	}
+
+	return res
+}
+
+func snippet(doc searchDoc, terms []string) string {
+	const width = 80
+
+	for _, field := range []string{"content", "description", "title"} {
+		text := doc.text[field]
+		lower := strings.ToLower(text)
+
+		if len(lower) != len(text) {
+			lower = text
+		}
+
+		for _, term := range terms {
+			term = strings.TrimSuffix(term, "*")
+			if term == "" {
+				continue
+			}
+
+			for i := strings.Index(lower, term); i >= 0; {
+				from, to := 0, len(text)
+
+				if i > width {
+					from = i - width
+					if j := strings.Index(text[from:i], " "); j >= 0 {
+						from += j
+					}
+				}
+
+				if i+len(term)+width < len(text) {
+					to = i + len(term) + width
+					if j := strings.LastIndex(text[i:to], " "); j > 0 {
+						to = i + j
+					}
+				}
+
+				if res := highlight(strings.TrimSpace(text[from:to]), terms); strings.Contains(res, "<mark>") {
+					return res
+				}
+
+				next := strings.Index(lower[i+len(term):], term)
+				if next < 0 {
+					break
+				}
+				i += len(term) + next
+			}
+		}
+	}
+
+	return ""
+}
+
+func highlight(text string, terms []string) string {
+	var (
+		b     strings.Builder
+		lower = strings.ToLower(text)
+		i     = 0
+		plain = 0
+	)
+
+	if len(lower) != len(text) {
+		lower = text
+	}
+
+	for i < len(text) {
+		matched := 0
+		for _, term := range terms {
+			prefix := strings.HasSuffix(term, "*")
+			term = strings.TrimSuffix(term, "*")
+			if term == "" || !strings.HasPrefix(lower[i:], term) || (i > 0 && isWordByte(lower[i-1])) {
+				continue
+			}
+			end := i + len(term)
+			if prefix {
+				for end < len(text) && isWordByte(lower[end]) {
+					end++
+				}
+			} else if end < len(text) && isWordByte(lower[end]) {
+				continue
+			}
+			if end-i > matched {
+				matched = end - i
+			}
+		}
+
+		if matched > 0 {
+			b.WriteString(html.EscapeString(text[plain:i]))
+			b.WriteString("<mark>" + html.EscapeString(text[i:i+matched]) + "</mark>")
+			i += matched
+			plain = i
+		} else {
+			i++
+		}
+	}
+
+	b.WriteString(html.EscapeString(text[plain:]))
+
+	return b.String()
+}
+
+func isWordByte(c byte) bool {
+	return c >= 0x80 || unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c))
+}
+
+func buildIndex() {
+	var elements Contentelements
+
+	App.DB.Preload("Contenttags").Find(&elements)
+
+	for _, v := range elements {
+		indexElement(v)
+	}
+}
+
+func actionSearch(w http.ResponseWriter, r *http.Request) {
+	var (
+		results Searchresults
+		rsp     = core.Response{Data: &results, Req: r}
+		q       = strings.TrimSpace(r.FormValue("q"))
+		limit   = 10
+		offset  = 0
+		ids     []uint
+		byID    = map[uint]Contentelement{}
+		db      = App.DB
+	)
+
+	if q == "" {
+		rsp.Errors.Add("q", "Search query is required")
+		w.Write(rsp.Make())
+		return
+	}
+
+	if v, err := strconv.Atoi(r.FormValue("limit")); err == nil && v > 0 {
+		limit = v
+	}
+
+	if v, err := strconv.Atoi(r.FormValue("offset")); err == nil && v > 0 {
+		offset = v
+	}
+
+	hits := Index.Search(q)
+
+	for _, hit := range hits {
+		ids = append(ids, hit.ID)
+	}
+
+	db = visibleIn(db, r)
+
+	if len(ids) != 0 {
+		var elements Contentelements
+		db.Preload("Contenttags").Where("id IN (?)", ids).Find(&elements)
+		for _, v := range translate(r, elements) {
+			byID[v.ID] = v
+		}
+	}
+
+	results = Searchresults{}
+
+	for _, hit := range hits {
+		if element, ok := byID[hit.ID]; ok {
+			results = append(results, Searchresult{Element: element, Score: hit.Score, Snippet: hit.Snippet})
+		}
+	}
+
+	rsp.Count = len(results)
+
+	if offset > len(results) {
+		offset = len(results)
+	}
+
+	if offset+limit < len(results) {
+		results = results[offset : offset+limit]
+	} else {
+		results = results[offset:]
+	}
+
+	rsp.Data = &results
+
+	w.Write(rsp.Make())
+}
@@ -0,0 +1,21 @@
+package contentelements
+
+import "testing"
+
+func TestHighlightEscapes(t *testing.T) {
+	cases := []struct {
+		text  string
+		terms []string
+		want  string
+	}{
+		{`<b>news</b> & more`, []string{"news"}, `&lt;b&gt;<mark>news</mark>&lt;/b&gt; &amp; more`},
+		{`say "hello" <script>`, []string{"hello"}, `say &#34;<mark>hello</mark>&#34; &lt;script&gt;`},
+		{`plain text`, []string{"none"}, `plain text`},
+	}
+
+	for _, c := range cases {
+		if got := highlight(c.text, c.terms); got != c.want {
+			t.Errorf("Wrong highlight of %q: %q, need %q", c.text, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package contentelements_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/go-rest-framework/contentelements"
+	"github.com/go-rest-framework/core"
+)
+
+type TestSearchresults struct {
+	Errors []core.ErrorMsg               `json:"errors"`
+	Data   contentelements.Searchresults `json:"data"`
+}
+
+func readSearchBody(r *http.Response, t *testing.T) TestSearchresults {
+	var u TestSearchresults
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		log.Fatal(err)
+	}
+	json.Unmarshal([]byte(body), &u)
+	return u
+}
+
+func searchHas(u TestSearchresults, id uint) bool {
+	for _, v := range u.Data {
+		if v.Element.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSearch(t *testing.T) {
+	//phrase query by full title
+	resp := doRequest(Murl+"/search?q="+url.QueryEscape("\""+NewsTwoTitle+"\""), "GET", "", "")
+
+	if resp.StatusCode != 200 {
+		t.Errorf("Success expected: %d", resp.StatusCode)
+	}
+
+	u := readSearchBody(resp, t)
+
+	if len(u.Errors) != 0 {
+		t.Fatal(u.Errors)
+	}
+
+	if !searchHas(u, CatId2) {
+		t.Errorf("Phrase search did not find element %d", CatId2)
+	}
+	//prefix query by tag
+	resp = doRequest(Murl+"/search?q="+url.QueryEscape(TwoTags[:len(TwoTags)-1]+"*"), "GET", "", "")
+
+	u = readSearchBody(resp, t)
+
+	if !searchHas(u, CatId2) {
+		t.Errorf("Prefix search did not find element %d", CatId2)
+	}
+	//snippets are highlighted
+	word := strings.Fields(NewsTwoTitle)[0]
+	resp = doRequest(Murl+"/search?q="+url.QueryEscape(word), "GET", "", "")
+
+	u = readSearchBody(resp, t)
+
+	for _, v := range u.Data {
+		if v.Snippet != "" && !strings.Contains(v.Snippet, "<mark>") {
+			t.Errorf("Snippet without highlight: %s", v.Snippet)
+		}
+	}
+	//empty query
+	resp = doRequest(Murl+"/search?q=", "GET", "", "")
+
+	u = readSearchBody(resp, t)
+
+	if len(u.Errors) == 0 {
+		t.Fatal("empty query validation dont work")
+	}
+
+	return
+}
@@ -0,0 +1,179 @@
+package contentelements
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-rest-framework/core"
+	"github.com/gorilla/mux"
+)
+
+func slugify(title string) string {
+	var (
+		b    strings.Builder
+		dash = false
+	)
+
+	for _, c := range strings.ToLower(title) {
+		switch {
+		case c >= 'a' && c <= 'z', c >= '0' && c <= '9':
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(c)
+			dash = false
+		default:
+			dash = true
+		}
+	}
+
+	if b.Len() == 0 {
+		return "element"
+	}
+
+	return b.String()
+}
+
+func urldTaken(urld string, parent int, id uint) bool {
+	var count int
+
+	App.DB.Model(&Contentelement{}).
+		Where("urld = ? AND parent = ? AND id <> ?", urld, parent, id).
+		Count(&count)
+
+	return count != 0
+}
+
+// urldError reports a failed write, usually a concurrent write that took the
+// same urld and hit uix_contentelements_parent_urld.
+func urldError(rsp *core.Response, err error, element Contentelement) bool {
+	if err == nil {
+		return false
+	}
+
+	if urldTaken(element.Urld, element.Parent, element.ID) {
+		rsp.Errors.Add("urld", "Urld already used by another element with the same parent")
+	} else {
+		rsp.Errors.Add("ID", err.Error())
+	}
+
+	return true
+}
+
+// deletedUrld frees the urld of a soft deleted element for the unique index.
+func deletedUrld(element Contentelement) string {
+	return fmt.Sprintf("%s~%d", element.Urld, element.ID)
+}
+
+// migrateSlugs frees the urlds of soft deleted rows and renames duplicate
+// siblings, keeping a redirect from the old path, then adds the unique index
+// AutoMigrate could not create over duplicates.
+func migrateSlugs() {
+	var (
+		dups     Contentelements
+		elements Contentelements
+	)
+
+	App.DB.Unscoped().Where("deleted_at IS NOT NULL AND urld NOT LIKE ?", "%~%").Select("id, urld").Find(&elements)
+
+	for _, v := range elements {
+		App.DB.Unscoped().Model(&v).UpdateColumn("urld", deletedUrld(v))
+	}
+
+	if App.DB.Dialect().HasIndex("contentelements", "uix_contentelements_parent_urld") {
+		return
+	}
+
+	App.DB.Model(&Contentelement{}).Select("parent, urld").Group("parent, urld").Having("COUNT(*) > 1").Find(&dups)
+
+	for _, d := range dups {
+		elements = nil
+		App.DB.Where("parent = ? AND urld = ?", d.Parent, d.Urld).Order("id").Select("id, urld, parent, path").Find(&elements)
+		for _, v := range elements[1:] {
+			oldPath := elementPath(v)
+			v.Urld = fmt.Sprintf("%s-%d", v.Urld, v.ID)
+			App.DB.Model(&v).UpdateColumn("urld", v.Urld)
+			addRedirect(oldPath, v)
+		}
+	}
+
+	App.DB.Model(&Contentelement{}).AddUniqueIndex("uix_contentelements_parent_urld", "parent", "urld")
+}
+
+func uniqueSlug(title string, parent int, id uint) string {
+	var (
+		base = slugify(title)
+		slug = base
+	)
+
+	for i := 2; urldTaken(slug, parent, id); i++ {
+		slug = fmt.Sprintf("%s-%d", base, i)
+	}
+
+	return slug
+}
+
+func checkUrld(rsp *core.Response, urld string, parent int, id uint) bool {
+	if strings.Contains(urld, "/") {
+		rsp.Errors.Add("urld", "Urld can not contain /")
+		return false
+	}
+
+	if urldTaken(urld, parent, id) {
+		rsp.Errors.Add("urld", "Urld already used by another element with the same parent")
+		return false
+	}
+
+	return true
+}
+
+func resolvePath(path string) Contentelement {
+	var (
+		element Contentelement
+		parent  = 0
+	)
+
+	for _, slug := range strings.Split(strings.Trim(path, "/"), "/") {
+		element = Contentelement{}
+		App.DB.Where("urld = ? AND parent = ?", slug, parent).First(&element)
+		if element.ID == 0 {
+			return element
+		}
+		parent = int(element.ID)
+	}
+
+	return element
+}
+
+func actionGetByUrl(w http.ResponseWriter, r *http.Request) {
+	var (
+		element Contentelement
+		rsp     = core.Response{Data: &element, Req: r}
+		vars    = mux.Vars(r)
+		db      = App.DB
+	)
+
+	element = resolvePath(vars["path"])
+
+	if element.ID != 0 {
+		db = db.Set("gorm:auto_preload", true)
+		db = db.Preload("Comments")
+		db.First(&element, element.ID)
+	}
+
+	if !readable(r, element) {
+		element = Contentelement{}
+	}
+
+	if element.ID == 0 {
+		rsp.Errors.Add("ID", "Contentelement not found")
+	} else {
+		element = translate(r, loadTree(visible(r), Contentelements{element}))[0]
+		element.Comments = withReactions(pruneComments(element.Comments, viewerOf(r)))
+		element.Reactions = reactionCounts("element", []uint{element.ID})[element.ID]
+		rsp.Data = &element
+	}
+
+	w.Write(rsp.Make())
+}
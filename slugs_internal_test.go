@@ -0,0 +1,74 @@
+package contentelements
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestUrldUniqueIndex(t *testing.T) {
+	defer testApp(t)()
+
+	first := Contentelement{Urld: "same", Title: "First", Status: "active"}
+
+	if err := App.DB.Create(&first).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := App.DB.Create(&Contentelement{Urld: "same", Title: "Second", Status: "active"}).Error; err == nil {
+		t.Errorf("Duplicate urld with the same parent is stored")
+	}
+
+	if err := App.DB.Create(&Contentelement{Urld: "same", Parent: int(first.ID), Title: "Child", Status: "active"}).Error; err != nil {
+		t.Errorf("Same urld with another parent is rejected: %s", err)
+	}
+
+	App.DB.Model(&first).UpdateColumn("urld", deletedUrld(first))
+	App.DB.Delete(&first)
+
+	if err := App.DB.Create(&Contentelement{Urld: "same", Title: "Third", Status: "active"}).Error; err != nil {
+		t.Errorf("Urld of deleted element is not reusable: %s", err)
+	}
+}
+
+func TestMigrateSlugs(t *testing.T) {
+	defer testApp(t)()
+
+	var (
+		first    = Contentelement{Urld: "same", Title: "First", Status: "active"}
+		second   = Contentelement{Urld: "same", Title: "Second", Status: "active"}
+		deleted  = Contentelement{Urld: "gone", Title: "Gone", Status: "active"}
+		redirect Contentredirect
+	)
+
+	App.DB.Model(&Contentelement{}).RemoveIndex("uix_contentelements_parent_urld")
+
+	for _, e := range []*Contentelement{&first, &second, &deleted} {
+		App.DB.Create(e)
+	}
+
+	App.DB.Delete(&deleted)
+
+	migrateSlugs()
+
+	App.DB.First(&first, first.ID)
+	App.DB.First(&second, second.ID)
+	App.DB.Unscoped().First(&deleted, deleted.ID)
+
+	if first.Urld != "same" || second.Urld != fmt.Sprintf("same-%d", second.ID) {
+		t.Errorf("Wrong urlds after deduplication: %s, %s", first.Urld, second.Urld)
+	}
+
+	if deleted.Urld != deletedUrld(Contentelement{Model: deleted.Model, Urld: "gone"}) {
+		t.Errorf("Urld of deleted element is not freed: %s", deleted.Urld)
+	}
+
+	App.DB.Where("path = ?", "/same").First(&redirect)
+
+	if redirect.ContentelementID != int(second.ID) {
+		t.Errorf("Redirect is not kept for renamed element")
+	}
+
+	if !App.DB.Dialect().HasIndex("contentelements", "uix_contentelements_parent_urld") {
+		t.Errorf("Unique index is not added after deduplication")
+	}
+}
@@ -0,0 +1,151 @@
+package contentelements_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/go-rest-framework/contentelements"
+)
+
+func getElement(t *testing.T, id uint) contentelements.Contentelement {
+	resp := doRequest(fmt.Sprintf("%s/%d", Murl, id), "GET", "", AdminToken)
+
+	u := readElementBody(resp, t)
+
+	if len(u.Errors) != 0 {
+		t.Fatal(u.Errors)
+	}
+
+	return u.Data
+}
+
+func TestGetByUrl(t *testing.T) {
+	cat := getElement(t, CatId1)
+	news := getElement(t, NewsOneId)
+
+	if news.Urld == "" {
+		t.Fatal("Urld was not generated from title")
+	}
+
+	resp := doRequest(Murl+"/by-url/"+cat.Urld+"/"+news.Urld, "GET", "", "")
+
+	if resp.StatusCode != 200 {
+		t.Errorf("Success expected: %d", resp.StatusCode)
+	}
+
+	u := readElementBody(resp, t)
+
+	if len(u.Errors) != 0 {
+		t.Fatal(u.Errors)
+	}
+
+	if u.Data.ID != NewsOneId {
+		t.Errorf("Wrong element by url: %d, need %d", u.Data.ID, NewsOneId)
+	}
+
+	resp = doRequest(Murl+"/by-url/"+news.Urld, "GET", "", "")
+
+	u = readElementBody(resp, t)
+
+	if len(u.Errors) == 0 {
+		t.Errorf("Nested element resolved without parent path")
+	}
+
+	return
+}
+
+func TestUrldUnique(t *testing.T) {
+	news := getElement(t, NewsOneId)
+
+	el := &contentelements.Contentelement{
+		Urld:   news.Urld,
+		Parent: int(CatId1),
+		Title:  news.Title,
+		Kind:   "standart",
+		Status: "active",
+	}
+
+	uj, err := json.Marshal(el)
+	if err != nil {
+		fmt.Printf("Error: %s", err)
+		return
+	}
+
+	resp := doRequest(Murl, "POST", string(uj), AdminToken)
+
+	u := readElementBody(resp, t)
+
+	if len(u.Errors) == 0 {
+		deleteElement(t, u.Data.ID)
+		t.Fatal("urld uniqueness among siblings dont work")
+	}
+
+	el.Urld = ""
+
+	uj, _ = json.Marshal(el)
+
+	resp = doRequest(Murl, "POST", string(uj), AdminToken)
+
+	u = readElementBody(resp, t)
+
+	if len(u.Errors) != 0 {
+		t.Fatal(u.Errors)
+	}
+
+	if u.Data.Urld == news.Urld {
+		t.Errorf("Generated urld is not unique: %s", u.Data.Urld)
+	}
+
+	deleteElement(t, u.Data.ID)
+
+	return
+}
+
+func TestUrldUniqueConcurrent(t *testing.T) {
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		created []uint
+	)
+
+	uj, err := json.Marshal(&contentelements.Contentelement{
+		Urld:   fmt.Sprintf("concurrent-%d", CatId2),
+		Parent: int(CatId2),
+		Title:  "Concurrent",
+		Kind:   "standart",
+		Status: "active",
+	})
+	if err != nil {
+		fmt.Printf("Error: %s", err)
+		return
+	}
+
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			u := readElementBody(doRequest(Murl, "POST", string(uj), AdminToken), t)
+
+			if len(u.Errors) == 0 {
+				mu.Lock()
+				created = append(created, u.Data.ID)
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if len(created) != 1 {
+		t.Errorf("Concurrent creates with the same urld: %d stored, need 1", len(created))
+	}
+
+	for _, id := range created {
+		deleteElement(t, id)
+	}
+
+	return
+}
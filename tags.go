@@ -0,0 +1,119 @@
+package contentelements
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/jinzhu/gorm"
+)
+
+type Taglist []string
+
+func (t *Taglist) UnmarshalJSON(b []byte) error {
+	var s string
+
+	if err := json.Unmarshal(b, &s); err == nil {
+		*t = splitTags(s)
+		return nil
+	}
+
+	var list []string
+
+	if err := json.Unmarshal(b, &list); err != nil {
+		return err
+	}
+
+	*t = list
+
+	return nil
+}
+
+func (t Taglist) String() string {
+	return strings.Join(t, ",")
+}
+
+func splitTags(s string) Taglist {
+	var (
+		list = Taglist{}
+		seen = map[string]bool{}
+	)
+
+	for _, v := range strings.Split(s, ",") {
+		v = strings.TrimSpace(v)
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		list = append(list, v)
+	}
+
+	return list
+}
+
+func (e *Contentelement) AfterFind() {
+	e.Tags = Taglist{}
+	for _, tag := range e.Contenttags {
+		e.Tags = append(e.Tags, tag.Name)
+	}
+}
+
+func setTags(element *Contentelement, names Taglist) {
+	var (
+		old  Contenttags
+		tags Contenttags
+		db   = App.DB.Model(element)
+	)
+
+	db.Association("Contenttags").Find(&old)
+
+	for _, name := range splitTags(names.String()) {
+		tag := Contenttag{Name: name}
+		App.DB.Where("name = ?", name).FirstOrCreate(&tag)
+		tags = append(tags, tag)
+	}
+
+	if len(tags) == 0 {
+		db.Association("Contenttags").Clear()
+	} else {
+		db.Association("Contenttags").Replace(tags)
+	}
+
+	element.Contenttags = tags
+	element.AfterFind()
+
+	updateTagWeights(append(old, tags...))
+}
+
+func updateTagWeights(tags Contenttags) {
+	for _, tag := range tags {
+		App.DB.Model(&Contenttag{}).Where("id = ?", tag.ID).UpdateColumn("weight", gorm.Expr(
+			"(SELECT COUNT(*) FROM contentelement_tags ct JOIN contentelements e ON e.id = ct.contentelement_id WHERE ct.contenttag_id = ? AND e.deleted_at IS NULL)",
+			tag.ID,
+		))
+	}
+}
+
+const tagsFilter = "contentelements.id IN (SELECT ct.contentelement_id FROM contentelement_tags ct JOIN contenttags t ON t.id = ct.contenttag_id WHERE t.name IN (?))"
+
+func migrateTags() {
+	type legacy struct {
+		ID   uint
+		Tags string
+	}
+
+	var rows []legacy
+
+	if !App.DB.Dialect().HasColumn("contentelements", "tags") {
+		return
+	}
+
+	App.DB.Table("contentelements").Select("id, tags").Where("tags <> ''").Scan(&rows)
+
+	for _, v := range rows {
+		element := Contentelement{}
+		element.ID = v.ID
+		setTags(&element, splitTags(v.Tags))
+	}
+
+	App.DB.Model(&Contentelement{}).DropColumn("tags")
+}
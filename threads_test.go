@@ -0,0 +1,94 @@
+package contentelements_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/go-rest-framework/contentelements"
+	"github.com/icrowley/fake"
+)
+
+func TestCommentThreads(t *testing.T) {
+	url := fmt.Sprintf("%s/%d/comments", Murl, NewsTwoId)
+
+	resp := doRequest(url, "POST", fmt.Sprintf(`{"comment":"%s","userID":100500}`, fake.Sentence()), UserToken)
+
+	c := readCommentBody(resp, t)
+
+	if len(c.Errors) != 0 {
+		t.Fatal(c.Errors)
+	}
+
+	if c.Data.UserID == 0 || c.Data.UserID == 100500 {
+		t.Errorf("Comment is not owned by authenticated user: %d", c.Data.UserID)
+	}
+
+	resp = doRequest(url, "POST", fmt.Sprintf(`{"comment":"%s","parent":%d}`, fake.Sentence(), CommentId), UserToken)
+
+	c = readCommentBody(resp, t)
+
+	if len(c.Errors) == 0 {
+		t.Errorf("Reply to comment of other element is accepted")
+	}
+
+	nested := fmt.Sprintf(`[{"comment":"%s","userID":100500,"contentelementID":%d,"status":"approved"}]`, fake.Sentence(), NewsOneId)
+
+	resp = doRequest(url, "POST", fmt.Sprintf(`{"comment":"%s","comments":%s}`, fake.Sentence(), nested), UserToken)
+
+	c = readCommentBody(resp, t)
+
+	if len(c.Errors) != 0 {
+		t.Fatal(c.Errors)
+	}
+
+	resp = doRequest(fmt.Sprintf("%s/%d", url, c.Data.ID), "PATCH", fmt.Sprintf(`{"comment":"%s","comments":%s}`, fake.Sentence(), nested), UserToken)
+
+	if u := readCommentBody(resp, t); len(u.Errors) != 0 {
+		t.Fatal(u.Errors)
+	}
+
+	resp = doRequest(url, "GET", "", AdminToken)
+
+	if found, ok := findComment(readCommentsBody(resp, t).Data, c.Data.ID); !ok || len(found.Comments) != 0 {
+		t.Errorf("Nested comments are stored with comment: %+v", found.Comments)
+	}
+
+	resp = doRequest(fmt.Sprintf("%s/%d/comments", Murl, 0xFFFFFFF), "POST", fmt.Sprintf(`{"comment":"%s"}`, fake.Sentence()), UserToken)
+
+	if resp.StatusCode != 404 {
+		t.Errorf("Not found expected: %d", resp.StatusCode)
+	}
+
+	c = readCommentBody(resp, t)
+
+	if len(c.Errors) == 0 {
+		t.Errorf("Comment to missing element is accepted")
+	}
+
+	return
+}
+
+func TestCommentDepth(t *testing.T) {
+	var (
+		url    = fmt.Sprintf("%s/%d/comments", Murl, NewsTwoId)
+		parent uint
+	)
+
+	for level := 1; level <= contentelements.MaxCommentDepth; level++ {
+		resp := doRequest(url, "POST", fmt.Sprintf(`{"comment":"%s","parent":%d}`, fake.Sentence(), parent), AdminToken)
+
+		c := readCommentBody(resp, t)
+
+		if len(c.Errors) != 0 {
+			t.Fatalf("Reply on level %d is rejected: %v", level, c.Errors)
+		}
+
+		parent = c.Data.ID
+	}
+
+	resp := doRequest(url, "POST", fmt.Sprintf(`{"comment":"%s","parent":%d}`, fake.Sentence(), parent), AdminToken)
+
+	if c := readCommentBody(resp, t); len(c.Errors) == 0 {
+		t.Errorf("Reply deeper than %d levels is accepted", contentelements.MaxCommentDepth)
+	}
+}
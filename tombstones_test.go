@@ -0,0 +1,85 @@
+package contentelements_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/go-rest-framework/contentelements"
+	"github.com/icrowley/fake"
+)
+
+func findComment(comments contentelements.Contentcomments, id uint) (contentelements.Contentcomment, bool) {
+	for _, v := range comments {
+		if v.ID == id {
+			return v, true
+		}
+	}
+
+	return contentelements.Contentcomment{}, false
+}
+
+func TestCommentTombstones(t *testing.T) {
+	url := fmt.Sprintf("%s/%d/comments", Murl, NewsTwoId)
+
+	resp := doRequest(url, "POST", fmt.Sprintf(`{"comment":"%s"}`, fake.Sentence()), UserToken)
+
+	root := readCommentBody(resp, t)
+
+	if len(root.Errors) != 0 {
+		t.Fatal(root.Errors)
+	}
+
+	resp = doRequest(url, "POST", fmt.Sprintf(`{"comment":"%s","parent":%d}`, fake.Sentence(), root.Data.ID), UserToken)
+
+	reply := readCommentBody(resp, t)
+
+	if len(reply.Errors) != 0 {
+		t.Fatal(reply.Errors)
+	}
+
+	resp = doRequest(fmt.Sprintf("%s/%d", url, root.Data.ID), "DELETE", "", UserToken)
+
+	if resp.StatusCode != 200 {
+		t.Errorf("Success expected: %d", resp.StatusCode)
+	}
+
+	resp = doRequest(url, "GET", "", "")
+
+	list := readCommentsBody(resp, t)
+
+	c, ok := findComment(list.Data, root.Data.ID)
+
+	if !ok || !c.Deleted || c.Comment != "[deleted]" {
+		t.Fatalf("Deleted comment with replies is not a tombstone: %+v", c)
+	}
+
+	if _, ok := findComment(c.Comments, reply.Data.ID); !ok {
+		t.Errorf("Reply of deleted comment is lost")
+	}
+
+	resp = doRequest(fmt.Sprintf("%s/%d/purge", url, root.Data.ID), "DELETE", "", UserToken)
+
+	resp = doRequest(url, "GET", "", "")
+
+	list = readCommentsBody(resp, t)
+
+	if _, ok := findComment(list.Data, root.Data.ID); !ok {
+		t.Errorf("Comment thread is purged by user")
+	}
+
+	resp = doRequest(fmt.Sprintf("%s/%d/purge", url, root.Data.ID), "DELETE", "", AdminToken)
+
+	if resp.StatusCode != 200 {
+		t.Errorf("Success expected: %d", resp.StatusCode)
+	}
+
+	resp = doRequest(url, "GET", "", "")
+
+	list = readCommentsBody(resp, t)
+
+	if _, ok := findComment(list.Data, root.Data.ID); ok {
+		t.Errorf("Comment thread is not purged")
+	}
+
+	return
+}
@@ -0,0 +1,388 @@
+package contentelements
+
+import (
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-rest-framework/core"
+	"github.com/gorilla/mux"
+	"github.com/jinzhu/gorm"
+)
+
+var localeRe = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{2,8})*$`)
+
+type Contentelementtranslations []Contentelementtranslation
+
+type Contentelementtranslation struct {
+	gorm.Model
+	ContentelementID int    `json:"contentelementID" gorm:"index"`
+	Locale           string `json:"locale" valid:"required"`
+	SourceRevision   int    `json:"source_revision"`
+	Title            string `json:"title" valid:"required"`
+	Description      string `json:"description" gorm:"type:varchar(500)"`
+	Content          string `json:"content" gorm:"type:text"`
+	Meta_title       string `json:"meta_title"`
+	Meta_descr       string `json:"meta_descr" gorm:"type:text"`
+}
+
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.Replace(strings.TrimSpace(locale), "_", "-", -1))
+}
+
+func localeChain(r *http.Request) []string {
+	type weighted struct {
+		locale string
+		q      float64
+	}
+
+	var (
+		chain []string
+		seen  = map[string]bool{}
+		langs []weighted
+	)
+
+	add := func(locale string) {
+		locale = normalizeLocale(locale)
+		if locale == "" || locale == "*" || seen[locale] {
+			return
+		}
+		seen[locale] = true
+		chain = append(chain, locale)
+	}
+
+	if lang := r.FormValue("lang"); lang != "" {
+		add(lang)
+	}
+
+	for _, part := range strings.Split(r.Header.Get("Accept-Language"), ",") {
+		fields := strings.Split(part, ";")
+		w := weighted{locale: fields[0], q: 1}
+		for _, f := range fields[1:] {
+			f = strings.TrimSpace(f)
+			if strings.HasPrefix(f, "q=") {
+				if q, err := strconv.ParseFloat(f[2:], 64); err == nil {
+					w.q = q
+				}
+			}
+		}
+		if w.q > 0 {
+			langs = append(langs, w)
+		}
+	}
+
+	sort.SliceStable(langs, func(i, j int) bool {
+		return langs[i].q > langs[j].q
+	})
+
+	for _, v := range langs {
+		add(v.locale)
+	}
+
+	for _, locale := range append([]string{}, chain...) {
+		if i := strings.Index(locale, "-"); i > 0 {
+			add(locale[:i])
+		}
+	}
+
+	return chain
+}
+
+func collectIDs(elements Contentelements, ids []uint) []uint {
+	for _, v := range elements {
+		ids = append(ids, v.ID)
+		ids = collectIDs(v.Elements, ids)
+	}
+
+	return ids
+}
+
+func (t Contentelementtranslation) applyTo(element *Contentelement, content bool) {
+	element.Lang = t.Locale
+	element.Title = t.Title
+	element.Description = t.Description
+	element.Meta_title = t.Meta_title
+	element.Meta_descr = t.Meta_descr
+	if content {
+		element.Content = t.Content
+	}
+}
+
+func applyTranslations(elements Contentelements, best map[uint]Contentelementtranslation, content bool) {
+	for i := range elements {
+		if t, ok := best[elements[i].ID]; ok {
+			t.applyTo(&elements[i], content)
+		}
+		applyTranslations(elements[i].Elements, best, false)
+	}
+}
+
+func translate(r *http.Request, elements Contentelements) Contentelements {
+	var (
+		chain = localeChain(r)
+		ids   = collectIDs(elements, nil)
+		list  Contentelementtranslations
+		best  = map[uint]Contentelementtranslation{}
+		rank  = map[string]int{}
+	)
+
+	if len(chain) == 0 || len(ids) == 0 {
+		return elements
+	}
+
+	for i, locale := range chain {
+		rank[locale] = i
+	}
+
+	App.DB.Where("contentelement_id IN (?) AND locale IN (?)", ids, chain).Find(&list)
+
+	for _, t := range list {
+		id := uint(t.ContentelementID)
+		if cur, ok := best[id]; !ok || rank[t.Locale] < rank[cur.Locale] {
+			best[id] = t
+		}
+	}
+
+	applyTranslations(elements, best, true)
+
+	return elements
+}
+
+func indexElement(element Contentelement) {
+	var list Contentelementtranslations
+
+	App.DB.Where("contentelement_id = ?", element.ID).Find(&list)
+
+	for _, t := range list {
+		element.Title += "\n" + t.Title
+		element.Description += "\n" + t.Description
+		element.Content += "\n" + t.Content
+	}
+
+	Index.Add(element)
+}
+
+func reindexElement(id uint) {
+	var element Contentelement
+
+	App.DB.Preload("Contenttags").First(&element, id)
+
+	if element.ID != 0 {
+		indexElement(element)
+	}
+}
+
+func actionTranslations(w http.ResponseWriter, r *http.Request) {
+	var (
+		element Contentelement
+		list    Contentelementtranslations
+		rsp     = core.Response{Data: &list, Req: r}
+		vars    = mux.Vars(r)
+	)
+
+	visible(r).First(&element, vars["id"])
+
+	if element.ID == 0 {
+		rsp.Errors.Add("ID", "Contentelement not found")
+	} else {
+		App.DB.Where("contentelement_id = ?", element.ID).Order("locale").Find(&list)
+	}
+
+	rsp.Data = &list
+
+	w.Write(rsp.Make())
+}
+
+func actionSaveTranslation(w http.ResponseWriter, r *http.Request) {
+	var (
+		element     Contentelement
+		data        Contentelementtranslation
+		translation Contentelementtranslation
+		rsp         = core.Response{Data: &data, Req: r}
+		vars        = mux.Vars(r)
+	)
+
+	if rsp.IsJsonParseDone(r.Body) && rsp.IsValidate() {
+		App.DB.First(&element, vars["id"])
+
+		data.Locale = normalizeLocale(data.Locale)
+
+		if element.ID == 0 {
+			rsp.Errors.Add("ID", "Contentelement not found")
+		} else if !canChange(r, "element.translate", element) {
+			rsp.Errors.Add("ID", "Not allowed to change element")
+		} else if !localeRe.MatchString(data.Locale) {
+			rsp.Errors.Add("locale", "Wrong locale")
+		} else {
+			App.DB.Where("contentelement_id = ? AND locale = ?", element.ID, data.Locale).First(&translation)
+
+			data.Model = translation.Model
+			data.ContentelementID = int(element.ID)
+
+			if data.SourceRevision == 0 || data.SourceRevision > latestRevision(element.ID) {
+				data.SourceRevision = latestRevision(element.ID)
+			}
+
+			App.DB.Save(&data)
+
+			translation = data
+			reindexElement(element.ID)
+		}
+	}
+
+	rsp.Data = &translation
+
+	w.Write(rsp.Make())
+}
+
+func actionDeleteTranslation(w http.ResponseWriter, r *http.Request) {
+	var (
+		element     Contentelement
+		translation Contentelementtranslation
+		rsp         = core.Response{Data: &translation, Req: r}
+		vars        = mux.Vars(r)
+	)
+
+	App.DB.First(&element, vars["id"])
+	App.DB.Where("contentelement_id = ? AND locale = ?", vars["id"], normalizeLocale(vars["lang"])).First(&translation)
+
+	if translation.ID == 0 {
+		rsp.Errors.Add("lang", "Translation not found")
+	} else if !canChange(r, "element.translate", element) {
+		rsp.Errors.Add("ID", "Not allowed to change element")
+	} else {
+		App.DB.Unscoped().Delete(&translation)
+		reindexElement(uint(translation.ContentelementID))
+	}
+
+	rsp.Data = &translation
+
+	w.Write(rsp.Make())
+}
+
+type Contenttranslationreport []Contenttranslationstate
+
+type Contenttranslationstate struct {
+	ID              uint   `json:"id"`
+	Title           string `json:"title"`
+	Urld            string `json:"urld"`
+	Path            string `json:"path"`
+	State           string `json:"state"`
+	SourceRevision  int    `json:"source_revision"`
+	CurrentRevision int    `json:"current_revision"`
+}
+
+func latestRevision(elementID uint) int {
+	var last Contentelementrevision
+
+	App.DB.Select("revision").Where("contentelement_id = ?", elementID).Order("revision DESC").First(&last)
+
+	return last.Revision
+}
+
+func (rev Contentelementrevision) sameSource(element Contentelement) bool {
+	return rev.Title == element.Title &&
+		rev.Description == element.Description &&
+		rev.Content == element.Content &&
+		rev.Meta_title == element.Meta_title &&
+		rev.Meta_descr == element.Meta_descr
+}
+
+func actionTranslationReport(w http.ResponseWriter, r *http.Request) {
+	type latest struct {
+		ContentelementID int
+		Revision         int
+	}
+
+	var (
+		res          = Contenttranslationreport{}
+		rsp          = core.Response{Data: &res, Req: r}
+		lang         = normalizeLocale(r.FormValue("lang"))
+		parent       = r.FormValue("parent")
+		elements     Contentelements
+		translations Contentelementtranslations
+		revisions    Contentelementrevisions
+		latests      []latest
+		current      = map[uint]int{}
+		byElement    = map[uint]Contentelementtranslation{}
+		sources      = map[uint]Contentelementrevision{}
+		query        []string
+		args         []interface{}
+		db           = App.DB
+	)
+
+	if !localeRe.MatchString(lang) {
+		rsp.Errors.Add("lang", "Wrong locale")
+		w.Write(rsp.Make())
+		return
+	}
+
+	if parent != "" {
+		var p Contentelement
+		App.DB.Select("id, path").First(&p, parent)
+		if p.ID == 0 {
+			rsp.Errors.Add("parent", "Parent element not found")
+			w.Write(rsp.Make())
+			return
+		}
+		db = db.Where("path LIKE ?", p.Path+"_%")
+	}
+
+	db.Order("path").Find(&elements)
+
+	App.DB.Where("locale = ?", lang).Find(&translations)
+
+	App.DB.Model(&Contentelementrevision{}).
+		Select("contentelement_id, MAX(revision) AS revision").
+		Group("contentelement_id").
+		Scan(&latests)
+
+	for _, v := range latests {
+		current[uint(v.ContentelementID)] = v.Revision
+	}
+
+	for _, t := range translations {
+		byElement[uint(t.ContentelementID)] = t
+		query = append(query, "(contentelement_id = ? AND revision = ?)")
+		args = append(args, t.ContentelementID, t.SourceRevision)
+	}
+
+	if len(query) != 0 {
+		App.DB.Where(strings.Join(query, " OR "), args...).Find(&revisions)
+	}
+
+	for _, v := range revisions {
+		sources[uint(v.ContentelementID)] = v
+	}
+
+	for _, v := range elements {
+		state := Contenttranslationstate{
+			ID:              v.ID,
+			Title:           v.Title,
+			Urld:            v.Urld,
+			Path:            v.Path,
+			CurrentRevision: current[v.ID],
+		}
+
+		t, ok := byElement[v.ID]
+
+		if !ok {
+			state.State = "missing"
+		} else {
+			state.SourceRevision = t.SourceRevision
+			if source, found := sources[v.ID]; found && source.sameSource(v) {
+				continue
+			}
+			state.State = "stale"
+		}
+
+		res = append(res, state)
+	}
+
+	rsp.Data = &res
+	rsp.Count = len(res)
+
+	w.Write(rsp.Make())
+}
@@ -0,0 +1,185 @@
+package contentelements_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/go-rest-framework/contentelements"
+	"github.com/go-rest-framework/core"
+	"github.com/icrowley/fake"
+)
+
+var TranslatedTitle string
+
+type TestContentelementtranslations struct {
+	Errors []core.ErrorMsg                            `json:"errors"`
+	Data   contentelements.Contentelementtranslations `json:"data"`
+}
+
+func readTranslationsBody(r *http.Response, t *testing.T) TestContentelementtranslations {
+	var u TestContentelementtranslations
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		log.Fatal(err)
+	}
+	json.Unmarshal([]byte(body), &u)
+	return u
+}
+
+func TestSaveTranslation(t *testing.T) {
+	url := fmt.Sprintf("%s/%d/translations", Murl, NewsOneId)
+
+	TranslatedTitle = fake.Title()
+
+	el := &contentelements.Contentelementtranslation{
+		Locale:  "de",
+		Title:   TranslatedTitle,
+		Content: fake.Paragraphs(),
+	}
+
+	uj, err := json.Marshal(el)
+	if err != nil {
+		fmt.Printf("Error: %s", err)
+		return
+	}
+
+	resp := doRequest(url, "POST", string(uj), AdminToken)
+
+	if resp.StatusCode != 200 {
+		t.Errorf("Success expected: %d", resp.StatusCode)
+	}
+
+	resp = doRequest(url, "GET", "", "")
+
+	u := readTranslationsBody(resp, t)
+
+	if len(u.Errors) != 0 {
+		t.Fatal(u.Errors)
+	}
+
+	if len(u.Data) != 1 || u.Data[0].Locale != "de" {
+		t.Errorf("Wrong translations list: %v", u.Data)
+	}
+
+	return
+}
+
+func TestReadTranslated(t *testing.T) {
+	url := fmt.Sprintf("%s/%d", Murl, NewsOneId)
+
+	resp := doRequest(url+"?lang=de", "GET", "", "")
+
+	u := readElementBody(resp, t)
+
+	if len(u.Errors) != 0 {
+		t.Fatal(u.Errors)
+	}
+
+	if u.Data.Title != TranslatedTitle || u.Data.Lang != "de" {
+		t.Errorf("Wrong translated title: %s (%s)", u.Data.Title, u.Data.Lang)
+	}
+
+	request, _ := http.NewRequest("GET", url, strings.NewReader(""))
+	request.Header.Set("Accept-Language", "fr;q=0.2, de-CH, en;q=0.5")
+
+	resp, err := http.DefaultClient.Do(request)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	u = readElementBody(resp, t)
+
+	if u.Data.Title != TranslatedTitle {
+		t.Errorf("Accept-Language fallback dont work: %s", u.Data.Title)
+	}
+
+	resp = doRequest(url+"?lang=xx", "GET", "", "")
+
+	u = readElementBody(resp, t)
+
+	if u.Data.Title != NewsOneOneTitle {
+		t.Errorf("Source title expected without translation: %s", u.Data.Title)
+	}
+
+	return
+}
+
+type TestContenttranslationreport struct {
+	Errors []core.ErrorMsg                          `json:"errors"`
+	Data   contentelements.Contenttranslationreport `json:"data"`
+}
+
+func readReport(t *testing.T) map[uint]string {
+	var (
+		u   TestContenttranslationreport
+		res = map[uint]string{}
+	)
+
+	url := fmt.Sprintf("%s/translations/report?lang=de&parent=%d", Murl, CatId1)
+
+	resp := doRequest(url, "GET", "", AdminToken)
+
+	if resp.StatusCode != 200 {
+		t.Errorf("Success expected: %d", resp.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		log.Fatal(err)
+	}
+	json.Unmarshal([]byte(body), &u)
+
+	if len(u.Errors) != 0 {
+		t.Fatal(u.Errors)
+	}
+
+	for _, v := range u.Data {
+		res[v.ID] = v.State
+	}
+
+	return res
+}
+
+func TestTranslationReport(t *testing.T) {
+	report := readReport(t)
+
+	if state, ok := report[NewsOneId]; ok {
+		t.Errorf("Up to date translation reported as %s", state)
+	}
+
+	if report[NewsTwoId] != "missing" {
+		t.Errorf("Missing translation not reported: %s", report[NewsTwoId])
+	}
+
+	el := getElement(t, NewsOneId)
+	el.Content = fake.Paragraphs()
+
+	uj, err := json.Marshal(el)
+	if err != nil {
+		fmt.Printf("Error: %s", err)
+		return
+	}
+
+	resp := doRequest(fmt.Sprintf("%s/%d", Murl, NewsOneId), "PATCH", string(uj), AdminToken)
+
+	u := readElementBody(resp, t)
+
+	if len(u.Errors) != 0 {
+		t.Fatal(u.Errors)
+	}
+
+	publishDraft(t, NewsOneId)
+
+	report = readReport(t)
+
+	if report[NewsOneId] != "stale" {
+		t.Errorf("Stale translation not reported: %s", report[NewsOneId])
+	}
+
+	return
+}
@@ -0,0 +1,211 @@
+package contentelements
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jinzhu/gorm"
+)
+
+const maxTreeDepth = 64
+
+const treeColumns = "id, created_at, updated_at, deleted_at, urld, user_id, parent, sort_order, path, title, description, kind, status, publish_at, unpublish_at"
+
+func parentPath(parent int) string {
+	var p Contentelement
+
+	if parent == 0 {
+		return "/"
+	}
+
+	App.DB.Unscoped().Select("id, path").First(&p, parent)
+
+	if p.Path == "" {
+		return "/"
+	}
+
+	return p.Path
+}
+
+func updatePath(element *Contentelement) {
+	var (
+		old  = element.Path
+		path = fmt.Sprintf("%s%d/", parentPath(element.Parent), element.ID)
+	)
+
+	if old == path {
+		return
+	}
+
+	element.Path = path
+
+	App.DB.Model(element).UpdateColumn("path", path)
+
+	if old != "" {
+		App.DB.Unscoped().Model(&Contentelement{}).
+			Where("path LIKE ? AND id <> ?", old+"%", element.ID).
+			UpdateColumn("path", gorm.Expr("CONCAT(?, SUBSTRING(path, ?))", path, len(old)+1))
+	}
+}
+
+func migratePaths() {
+	var (
+		elements Contentelements
+		children = map[int]Contentelements{}
+		ids      = map[uint]bool{}
+		parents  = map[uint]int{}
+		visited  = map[uint]bool{}
+	)
+
+	var count int
+
+	App.DB.Unscoped().Model(&Contentelement{}).Where("path = '' OR path IS NULL").Count(&count)
+
+	if count == 0 {
+		return
+	}
+
+	App.DB.Unscoped().Select("id, parent").Find(&elements)
+
+	for _, v := range elements {
+		ids[v.ID] = true
+	}
+
+	for _, v := range elements {
+		parent := v.Parent
+		if !ids[uint(parent)] {
+			parent = 0
+		}
+		children[parent] = append(children[parent], v)
+		parents[v.ID] = parent
+	}
+
+	var walk func(id uint, path string)
+
+	walk = func(id uint, path string) {
+		visited[id] = true
+		p := fmt.Sprintf("%s%d/", path, id)
+		App.DB.Unscoped().Model(&Contentelement{}).Where("id = ?", id).UpdateColumn("path", p)
+		for _, v := range children[int(id)] {
+			if !visited[v.ID] {
+				walk(v.ID, p)
+			}
+		}
+	}
+
+	for _, v := range children[0] {
+		walk(v.ID, "/")
+	}
+
+	// rows left over hang off a parent cycle: break it at the row where
+	// following the parents repeats and attach that row to root
+	for _, v := range elements {
+		if visited[v.ID] {
+			continue
+		}
+		id, seen := v.ID, map[uint]bool{}
+		for !seen[id] {
+			seen[id] = true
+			id = uint(parents[id])
+		}
+		App.DB.Unscoped().Model(&Contentelement{}).Where("id = ?", id).UpdateColumn("parent", 0)
+		walk(id, "/")
+	}
+}
+
+func pathIDs(path string) []uint {
+	var ids []uint
+
+	for _, v := range strings.Split(strings.Trim(path, "/"), "/") {
+		if id, err := strconv.Atoi(v); err == nil {
+			ids = append(ids, uint(id))
+		}
+	}
+
+	return ids
+}
+
+func ancestors(db *gorm.DB, element Contentelement) Contentelements {
+	var (
+		res   = Contentelements{}
+		found Contentelements
+		byID  = map[uint]Contentelement{}
+		ids   = pathIDs(element.Path)
+	)
+
+	if len(ids) > 0 {
+		ids = ids[:len(ids)-1]
+	}
+
+	if len(ids) == 0 {
+		return res
+	}
+
+	db.Select(treeColumns).Where("id IN (?)", ids).Find(&found)
+
+	for _, v := range found {
+		byID[v.ID] = v
+	}
+
+	for _, id := range ids {
+		if v, ok := byID[id]; ok {
+			res = append(res, v)
+		}
+	}
+
+	return res
+}
+
+func loadTree(db *gorm.DB, roots Contentelements) Contentelements {
+	var (
+		descendants Contentelements
+		children    = map[int]Contentelements{}
+		query       []string
+		args        []interface{}
+	)
+
+	for _, v := range roots {
+		if v.Path == "" {
+			continue
+		}
+		query = append(query, "path LIKE ?")
+		args = append(args, v.Path+"_%")
+	}
+
+	if len(query) != 0 {
+		db.Select(treeColumns).
+			Preload("Contenttags").
+			Where(strings.Join(query, " OR "), args...).
+			Find(&descendants)
+	}
+
+	for _, v := range descendants {
+		children[v.Parent] = append(children[v.Parent], v)
+	}
+
+	var attach func(elements Contentelements, depth int) Contentelements
+
+	attach = func(elements Contentelements, depth int) Contentelements {
+		if depth > maxTreeDepth {
+			return elements
+		}
+		sort.SliceStable(elements, func(i, j int) bool {
+			if elements[i].SortOrder == elements[j].SortOrder {
+				return elements[i].ID < elements[j].ID
+			}
+			return elements[i].SortOrder < elements[j].SortOrder
+		})
+		for i := range elements {
+			elements[i].Elements = attach(append(Contentelements{}, children[int(elements[i].ID)]...), depth+1)
+		}
+		return elements
+	}
+
+	for i := range roots {
+		roots[i].Elements = attach(append(Contentelements{}, children[int(roots[i].ID)]...), 0)
+	}
+
+	return roots
+}
@@ -0,0 +1,46 @@
+package contentelements
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestMigratePathsCycle(t *testing.T) {
+	defer testApp(t)()
+
+	var (
+		root  = Contentelement{Urld: "root", Title: "Root", Status: "active"}
+		a     = Contentelement{Urld: "a", Title: "A", Status: "active"}
+		b     = Contentelement{Urld: "b", Title: "B", Status: "active"}
+		c     = Contentelement{Urld: "c", Title: "C", Status: "active"}
+		count int
+	)
+
+	for _, e := range []*Contentelement{&root, &a, &b, &c} {
+		App.DB.Create(e)
+	}
+
+	App.DB.Model(&a).UpdateColumn("parent", b.ID)
+	App.DB.Model(&b).UpdateColumn("parent", a.ID)
+	App.DB.Model(&c).UpdateColumn("parent", b.ID)
+
+	migratePaths()
+
+	App.DB.Model(&Contentelement{}).Where("path = '' OR path IS NULL").Count(&count)
+
+	if count != 0 {
+		t.Errorf("Elements without path after migration: %d", count)
+	}
+
+	for _, e := range []*Contentelement{&a, &b, &c} {
+		App.DB.First(e, e.ID)
+	}
+
+	if a.Parent != 0 || a.Path != fmt.Sprintf("/%d/", a.ID) {
+		t.Errorf("Cycle is not attached to root: parent %d, path %s", a.Parent, a.Path)
+	}
+
+	if b.Path != fmt.Sprintf("%s%d/", a.Path, b.ID) || c.Path != fmt.Sprintf("%s%d/", b.Path, c.ID) {
+		t.Errorf("Wrong paths below broken cycle: %s, %s", b.Path, c.Path)
+	}
+}
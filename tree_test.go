@@ -0,0 +1,88 @@
+package contentelements_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/go-rest-framework/contentelements"
+	"github.com/go-rest-framework/core"
+)
+
+type TestContentbreadcrumbs struct {
+	Errors []core.ErrorMsg                    `json:"errors"`
+	Data   contentelements.Contentbreadcrumbs `json:"data"`
+}
+
+func readBreadcrumbsBody(r *http.Response, t *testing.T) TestContentbreadcrumbs {
+	var u TestContentbreadcrumbs
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		log.Fatal(err)
+	}
+	json.Unmarshal([]byte(body), &u)
+	return u
+}
+
+func TestTreePath(t *testing.T) {
+	cat := getElement(t, CatId1)
+
+	if cat.Path != fmt.Sprintf("/%d/", CatId1) {
+		t.Errorf("Wrong root path: %s", cat.Path)
+	}
+
+	if len(cat.Elements) < 2 {
+		t.Fatalf("Wrong subtree count: %d, need >= 2", len(cat.Elements))
+	}
+
+	for _, v := range cat.Elements {
+		if !strings.HasPrefix(v.Path, cat.Path) || v.Path == cat.Path {
+			t.Errorf("Wrong child path: %s, parent %s", v.Path, cat.Path)
+		}
+		if v.Content != "" {
+			t.Errorf("Descendant %d loaded with content body", v.ID)
+		}
+	}
+
+	return
+}
+
+func TestAncestors(t *testing.T) {
+	url := fmt.Sprintf("%s/%d/ancestors", Murl, NewsOneId)
+
+	resp := doRequest(url, "GET", "", "")
+
+	if resp.StatusCode != 200 {
+		t.Errorf("Success expected: %d", resp.StatusCode)
+	}
+
+	u := readBreadcrumbsBody(resp, t)
+
+	if len(u.Errors) != 0 {
+		t.Fatal(u.Errors)
+	}
+
+	if len(u.Data) != 2 || u.Data[0].ID != CatId1 || u.Data[1].ID != NewsOneId {
+		t.Errorf("Wrong ancestors chain: %v", u.Data)
+	}
+
+	url = fmt.Sprintf("%s/%d?include=breadcrumbs", Murl, NewsOneId)
+
+	resp = doRequest(url, "GET", "", "")
+
+	e := readElementBody(resp, t)
+
+	if len(e.Errors) != 0 {
+		t.Fatal(e.Errors)
+	}
+
+	if len(e.Data.Breadcrumbs) != 2 || e.Data.Breadcrumbs[0].ID != CatId1 {
+		t.Errorf("Wrong embedded breadcrumbs: %v", e.Data.Breadcrumbs)
+	}
+
+	return
+}
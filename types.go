@@ -0,0 +1,445 @@
+package contentelements
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-rest-framework/core"
+	"github.com/gorilla/mux"
+	"github.com/jinzhu/gorm"
+)
+
+type Contentfields map[string]interface{}
+
+func (f Contentfields) Value() (driver.Value, error) {
+	if f == nil {
+		return nil, nil
+	}
+
+	b, err := json.Marshal(f)
+
+	return string(b), err
+}
+
+func (f *Contentfields) Scan(value interface{}) error {
+	var b []byte
+
+	switch v := value.(type) {
+	case nil:
+		*f = nil
+		return nil
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return errors.New("unsupported type for Contentfields")
+	}
+
+	if len(b) == 0 {
+		*f = nil
+		return nil
+	}
+
+	return json.Unmarshal(b, f)
+}
+
+type Contenttypefields []Contenttypefield
+
+type Contenttypefield struct {
+	Name     string   `json:"name"`
+	Type     string   `json:"type"`
+	Required bool     `json:"required"`
+	Min      *float64 `json:"min,omitempty"`
+	Max      *float64 `json:"max,omitempty"`
+	Pattern  string   `json:"pattern,omitempty"`
+	Options  []string `json:"options,omitempty"`
+}
+
+func (f Contenttypefields) Value() (driver.Value, error) {
+	b, err := json.Marshal(f)
+
+	return string(b), err
+}
+
+func (f *Contenttypefields) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, f)
+	case string:
+		return json.Unmarshal([]byte(v), f)
+	}
+
+	*f = nil
+
+	return nil
+}
+
+type Contenttypes []Contenttype
+
+type Contenttype struct {
+	gorm.Model
+	Name      string            `json:"name" valid:"required" gorm:"unique_index"`
+	Title     string            `json:"title"`
+	Fields    Contenttypefields `json:"fields" gorm:"type:text"`
+	Moderated *bool             `json:"moderated,omitempty"`
+}
+
+var fieldTypes = map[string]bool{
+	"string":    true,
+	"int":       true,
+	"float":     true,
+	"bool":      true,
+	"datetime":  true,
+	"reference": true,
+}
+
+var (
+	typesMu sync.RWMutex
+	types   = map[string]Contenttype{}
+)
+
+func RegisterType(t Contenttype) error {
+	var existing Contenttype
+
+	if err := t.check(); err != nil {
+		return err
+	}
+
+	App.DB.Where("name = ?", t.Name).First(&existing)
+
+	if existing.ID != 0 {
+		t.Model = existing.Model
+		App.DB.Save(&t)
+	} else {
+		App.DB.Create(&t)
+	}
+
+	typesMu.Lock()
+	first := len(types) == 0
+	types[t.Name] = t
+	typesMu.Unlock()
+
+	if first {
+		registerKinds()
+	}
+
+	return nil
+}
+
+// registerKinds declares the kinds of existing elements that have no content
+// type yet, so they stay valid once kinds are enforced.
+func registerKinds() {
+	var kinds []string
+
+	App.DB.Model(&Contentelement{}).Where("kind <> ''").Pluck("DISTINCT kind", &kinds)
+
+	for _, kind := range kinds {
+		if _, found := FindType(kind); found {
+			continue
+		}
+
+		t := Contenttype{Name: kind, Title: kind}
+
+		if App.DB.Create(&t).Error == nil {
+			typesMu.Lock()
+			types[kind] = t
+			typesMu.Unlock()
+		}
+	}
+}
+
+func FindType(name string) (Contenttype, bool) {
+	typesMu.RLock()
+	defer typesMu.RUnlock()
+
+	t, ok := types[name]
+
+	return t, ok
+}
+
+func loadTypes() {
+	var list Contenttypes
+
+	App.DB.Find(&list)
+
+	typesMu.Lock()
+	for _, v := range list {
+		types[v.Name] = v
+	}
+	typesMu.Unlock()
+
+	if len(list) != 0 {
+		registerKinds()
+	}
+}
+
+func (t Contenttype) check() error {
+	var seen = map[string]bool{}
+
+	if t.Name == "" {
+		return errors.New("Content type name is required")
+	}
+
+	for _, f := range t.Fields {
+		if f.Name == "" {
+			return errors.New("Field name is required")
+		}
+		if seen[f.Name] {
+			return fmt.Errorf("Field %s is declared twice", f.Name)
+		}
+		if !fieldTypes[f.Type] {
+			return fmt.Errorf("Field %s has unknown type %s", f.Name, f.Type)
+		}
+		if f.Pattern != "" {
+			if _, err := regexp.Compile(f.Pattern); err != nil {
+				return fmt.Errorf("Field %s has wrong pattern: %s", f.Name, err)
+			}
+		}
+		seen[f.Name] = true
+	}
+
+	return nil
+}
+
+func (f Contenttypefield) validate(v interface{}) error {
+	var n float64
+
+	switch f.Type {
+	case "string":
+		s, ok := v.(string)
+		if !ok {
+			return errors.New("must be a string")
+		}
+		if f.Pattern != "" && !regexp.MustCompile(f.Pattern).MatchString(s) {
+			return errors.New("does not match pattern")
+		}
+		if len(f.Options) != 0 {
+			found := false
+			for _, o := range f.Options {
+				found = found || o == s
+			}
+			if !found {
+				return errors.New("is not one of allowed options")
+			}
+		}
+		n = float64(len(s))
+	case "int", "float", "reference":
+		x, ok := v.(float64)
+		if !ok {
+			return errors.New("must be a number")
+		}
+		if f.Type != "float" && x != math.Trunc(x) {
+			return errors.New("must be an integer")
+		}
+		if f.Type == "reference" {
+			var count int
+			App.DB.Model(&Contentelement{}).Where("id = ?", int(x)).Count(&count)
+			if count == 0 {
+				return errors.New("references missing element")
+			}
+		}
+		n = x
+	case "bool":
+		if _, ok := v.(bool); !ok {
+			return errors.New("must be a boolean")
+		}
+		return nil
+	case "datetime":
+		s, ok := v.(string)
+		if !ok {
+			return errors.New("must be a RFC3339 datetime string")
+		}
+		d, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return errors.New("must be a RFC3339 datetime string")
+		}
+		n = float64(d.Unix())
+	}
+
+	if f.Min != nil && n < *f.Min {
+		return fmt.Errorf("must not be less than %v", *f.Min)
+	}
+
+	if f.Max != nil && n > *f.Max {
+		return fmt.Errorf("must not be greater than %v", *f.Max)
+	}
+
+	return nil
+}
+
+func checkFields(rsp *core.Response, kind string, fields Contentfields) bool {
+	var (
+		ok       = true
+		declared = map[string]bool{}
+		names    []string
+	)
+
+	typesMu.RLock()
+	empty := len(types) == 0
+	typesMu.RUnlock()
+
+	t, found := FindType(kind)
+
+	if !found {
+		if empty && len(fields) == 0 {
+			return true
+		}
+		rsp.Errors.Add("kind", "Unknown content type "+kind)
+		return false
+	}
+
+	for _, f := range t.Fields {
+		declared[f.Name] = true
+		v, set := fields[f.Name]
+		if !set || v == nil {
+			if f.Required {
+				rsp.Errors.Add("fields."+f.Name, "Field is required")
+				ok = false
+			}
+			continue
+		}
+		if err := f.validate(v); err != nil {
+			rsp.Errors.Add("fields."+f.Name, "Field "+err.Error())
+			ok = false
+		}
+	}
+
+	for name := range fields {
+		if !declared[name] {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		rsp.Errors.Add("fields."+name, "Field is not declared for "+kind)
+		ok = false
+	}
+
+	return ok
+}
+
+func actionTypes(w http.ResponseWriter, r *http.Request) {
+	var (
+		list Contenttypes
+		rsp  = core.Response{Data: &list, Req: r}
+	)
+
+	App.DB.Order("name").Find(&list)
+
+	rsp.Data = &list
+
+	w.Write(rsp.Make())
+}
+
+func actionType(w http.ResponseWriter, r *http.Request) {
+	var (
+		t    Contenttype
+		rsp  = core.Response{Data: &t, Req: r}
+		vars = mux.Vars(r)
+	)
+
+	App.DB.Where("name = ?", vars["name"]).First(&t)
+
+	if t.ID == 0 {
+		rsp.Errors.Add("name", "Content type not found")
+	}
+
+	rsp.Data = &t
+
+	w.Write(rsp.Make())
+}
+
+func actionCreateType(w http.ResponseWriter, r *http.Request) {
+	var (
+		t   Contenttype
+		rsp = core.Response{Data: &t, Req: r}
+	)
+
+	if rsp.IsJsonParseDone(r.Body) && rsp.IsValidate() {
+		if _, found := FindType(t.Name); found {
+			rsp.Errors.Add("name", "Content type already exists")
+		} else if err := RegisterType(t); err != nil {
+			rsp.Errors.Add("fields", err.Error())
+		} else {
+			t, _ = FindType(t.Name)
+		}
+	}
+
+	rsp.Data = &t
+
+	w.Write(rsp.Make())
+}
+
+func actionUpdateType(w http.ResponseWriter, r *http.Request) {
+	var (
+		data Contenttype
+		t    Contenttype
+		rsp  = core.Response{Data: &data, Req: r}
+		vars = mux.Vars(r)
+	)
+
+	if rsp.IsJsonParseDone(r.Body) {
+		if t, found := FindType(vars["name"]); !found {
+			rsp.Errors.Add("name", "Content type not found")
+		} else {
+			if data.Title != "" {
+				t.Title = data.Title
+			}
+			if data.Fields != nil {
+				t.Fields = data.Fields
+			}
+			if data.Moderated != nil {
+				t.Moderated = data.Moderated
+			}
+			if err := RegisterType(t); err != nil {
+				rsp.Errors.Add("fields", err.Error())
+			}
+		}
+	}
+
+	t, _ = FindType(vars["name"])
+
+	rsp.Data = &t
+
+	w.Write(rsp.Make())
+}
+
+func actionDeleteType(w http.ResponseWriter, r *http.Request) {
+	var (
+		t     Contenttype
+		count int
+		rsp   = core.Response{Data: &t, Req: r}
+		vars  = mux.Vars(r)
+	)
+
+	App.DB.Where("name = ?", vars["name"]).First(&t)
+
+	if t.ID == 0 {
+		rsp.Errors.Add("name", "Content type not found")
+	} else {
+		App.DB.Model(&Contentelement{}).Where("kind = ?", t.Name).Count(&count)
+		if count != 0 {
+			rsp.Errors.Add("name", "Content type is used by elements")
+		} else {
+			App.DB.Unscoped().Delete(&t)
+			typesMu.Lock()
+			delete(types, t.Name)
+			typesMu.Unlock()
+		}
+	}
+
+	rsp.Data = &t
+
+	w.Write(rsp.Make())
+}
@@ -0,0 +1,44 @@
+package contentelements
+
+import (
+	"testing"
+
+	"github.com/go-rest-framework/core"
+)
+
+func TestLegacyKinds(t *testing.T) {
+	defer testApp(t)()
+
+	typesMu.Lock()
+	saved := types
+	types = map[string]Contenttype{}
+	typesMu.Unlock()
+
+	defer func() {
+		typesMu.Lock()
+		types = saved
+		typesMu.Unlock()
+	}()
+
+	App.DB.Create(&Contentelement{Urld: "legacy", Title: "Legacy", Kind: "legacy", Status: "active"})
+
+	if err := RegisterType(Contenttype{Name: "event", Title: "Event"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if !checkFields(&core.Response{}, "legacy", nil) {
+		t.Errorf("Kind of existing elements is rejected after first type is registered")
+	}
+
+	if checkFields(&core.Response{}, "unknown", nil) {
+		t.Errorf("Unknown kind is not rejected")
+	}
+
+	var count int
+
+	App.DB.Model(&Contenttype{}).Where("name = ?", "legacy").Count(&count)
+
+	if count != 1 {
+		t.Errorf("Kind of existing elements is not stored as content type")
+	}
+}
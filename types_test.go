@@ -0,0 +1,164 @@
+package contentelements_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-rest-framework/contentelements"
+	"github.com/go-rest-framework/core"
+	"github.com/icrowley/fake"
+)
+
+var TypeName string
+
+type TestContenttype struct {
+	Errors []core.ErrorMsg             `json:"errors"`
+	Data   contentelements.Contenttype `json:"data"`
+}
+
+func readTypeBody(r *http.Response, t *testing.T) TestContenttype {
+	var u TestContenttype
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		log.Fatal(err)
+	}
+	json.Unmarshal([]byte(body), &u)
+	return u
+}
+
+func createTyped(t *testing.T, kind string, fields contentelements.Contentfields) TestContentelement {
+	el := &contentelements.Contentelement{
+		Title:  fake.Title(),
+		Kind:   kind,
+		Status: "active",
+		Fields: fields,
+	}
+
+	uj, err := json.Marshal(el)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := doRequest(Murl, "POST", string(uj), AdminToken)
+
+	if resp.StatusCode != 200 {
+		t.Errorf("Success expected: %d", resp.StatusCode)
+	}
+
+	return readElementBody(resp, t)
+}
+
+func TestCreateType(t *testing.T) {
+	url := "http://localhost/api/contenttypes"
+
+	TypeName = fmt.Sprintf("event%d", time.Now().UnixNano())
+
+	resp := doRequest(url, "POST", fmt.Sprintf(`{"name":"%s","title":"Event","fields":[
+		{"name":"price","type":"int","required":true,"min":0},
+		{"name":"starts","type":"datetime"},
+		{"name":"level","type":"string","options":["low","high"]}
+	]}`, TypeName), AdminToken)
+
+	if resp.StatusCode != 200 {
+		t.Errorf("Success expected: %d", resp.StatusCode)
+	}
+
+	u := readTypeBody(resp, t)
+
+	if len(u.Errors) != 0 {
+		t.Fatal(u.Errors)
+	}
+
+	if len(u.Data.Fields) != 3 {
+		t.Errorf("Wrong fields count: %d, need 3", len(u.Data.Fields))
+	}
+
+	resp = doRequest(url, "POST", `{"name":"broken","fields":[{"name":"x","type":"money"}]}`, AdminToken)
+
+	u = readTypeBody(resp, t)
+
+	if len(u.Errors) == 0 {
+		t.Errorf("Unknown field type is not rejected")
+	}
+
+	return
+}
+
+func TestTypedElement(t *testing.T) {
+	u := createTyped(t, TypeName, contentelements.Contentfields{
+		"price":  10,
+		"starts": "2030-01-02T10:00:00Z",
+		"level":  "low",
+	})
+
+	if len(u.Errors) != 0 {
+		t.Fatal(u.Errors)
+	}
+
+	deleteElement(t, u.Data.ID)
+
+	u = createTyped(t, TypeName, contentelements.Contentfields{"price": 1.5})
+
+	if len(u.Errors) == 0 {
+		t.Errorf("Wrong int value is not rejected")
+	}
+
+	u = createTyped(t, TypeName, contentelements.Contentfields{"level": "low"})
+
+	if len(u.Errors) == 0 {
+		t.Errorf("Missing required field is not rejected")
+	}
+
+	u = createTyped(t, TypeName, contentelements.Contentfields{"price": 1, "color": "red"})
+
+	if len(u.Errors) == 0 {
+		t.Errorf("Undeclared field is not rejected")
+	}
+
+	u = createTyped(t, "News"+TypeName, nil)
+
+	if len(u.Errors) == 0 {
+		t.Errorf("Unknown kind is not rejected")
+	}
+
+	return
+}
+
+func TestFieldFilters(t *testing.T) {
+	price := int(time.Now().Unix())
+
+	one := createTyped(t, TypeName, contentelements.Contentfields{"price": price, "level": "low"})
+	two := createTyped(t, TypeName, contentelements.Contentfields{"price": price + 10, "level": "high"})
+
+	if len(one.Errors) != 0 || len(two.Errors) != 0 {
+		t.Fatal(one.Errors, two.Errors)
+	}
+
+	u := GetOne(t, Murl+fmt.Sprintf("?tree=-1&limit=100&field.price>=%d&sort=-field.price", price))
+
+	if len(u.Data) != 2 || u.Data[0].ID != two.Data.ID || u.Data[1].ID != one.Data.ID {
+		t.Errorf("Wrong field filter and sort result: %d elements", len(u.Data))
+	}
+
+	u = GetOne(t, Murl+fmt.Sprintf("?tree=-1&limit=100&field.price>%d", price))
+
+	if len(u.Data) != 1 || u.Data[0].ID != two.Data.ID {
+		t.Errorf("Wrong strict field filter result: %d elements", len(u.Data))
+	}
+
+	u = GetOne(t, Murl+fmt.Sprintf("?tree=-1&limit=100&field.price>=%d&field.level=low", price))
+
+	if len(u.Data) != 1 || u.Data[0].ID != one.Data.ID {
+		t.Errorf("Wrong string field filter result: %d elements", len(u.Data))
+	}
+
+	deleteElement(t, one.Data.ID)
+	deleteElement(t, two.Data.ID)
+
+	return
+}
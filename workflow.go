@@ -0,0 +1,164 @@
+package contentelements
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-rest-framework/core"
+	"github.com/gorilla/mux"
+	"github.com/jinzhu/gorm"
+)
+
+type Workflow struct {
+	States      []string             `json:"states"`
+	Transitions []Workflowtransition `json:"transitions"`
+}
+
+type Workflowtransition struct {
+	Name  string   `json:"name"`
+	From  []string `json:"from"`
+	To    string   `json:"to"`
+	Roles []string `json:"roles"`
+}
+
+var Editorial = Workflow{
+	States: []string{"draft", "review", "active", "suspend"},
+	Transitions: []Workflowtransition{
+		{Name: "submit", From: []string{"draft"}, To: "review", Roles: []string{"user", "admin"}},
+		{Name: "approve", From: []string{"review"}, To: "active", Roles: []string{"admin"}},
+		{Name: "reject", From: []string{"review"}, To: "draft", Roles: []string{"admin"}},
+		{Name: "publish", From: []string{"draft", "suspend"}, To: "active", Roles: []string{"admin"}},
+		{Name: "suspend", From: []string{"active"}, To: "suspend", Roles: []string{"admin"}},
+		{Name: "withdraw", From: []string{"review", "active", "suspend"}, To: "draft", Roles: []string{"admin"}},
+	},
+}
+
+type Contenttransitions []Contenttransition
+
+type Contenttransition struct {
+	gorm.Model
+	ContentelementID int    `json:"contentelementID" gorm:"index"`
+	UserID           int    `json:"userID"`
+	Transition       string `json:"transition"`
+	From             string `json:"from"`
+	To               string `json:"to"`
+	Comment          string `json:"comment" gorm:"type:varchar(500)"`
+}
+
+func hasState(status string, list []string) bool {
+	for _, v := range list {
+		if v == status {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (wf Workflow) find(name string) (Workflowtransition, bool) {
+	for _, t := range wf.Transitions {
+		if t.Name == name {
+			return t, true
+		}
+	}
+
+	return Workflowtransition{}, false
+}
+
+func (t Workflowtransition) allows(viewer Viewer) bool {
+	for _, role := range viewer.roles() {
+		if hasState(role, t.Roles) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func applyTransition(element *Contentelement, t Workflowtransition, userID int, comment string) {
+	audit := Contenttransition{
+		ContentelementID: int(element.ID),
+		UserID:           userID,
+		Transition:       t.Name,
+		From:             element.Status,
+		To:               t.To,
+		Comment:          comment,
+	}
+
+	App.DB.Model(element).UpdateColumn("status", t.To)
+	App.DB.Create(&audit)
+	saveRevision(element, userID)
+}
+
+func checkStatus(rsp *core.Response, status string) bool {
+	if !hasState(status, Editorial.States) {
+		rsp.Errors.Add("status", "Status "+status+" is not allowed")
+		return false
+	}
+
+	return true
+}
+
+func actionTransition(w http.ResponseWriter, r *http.Request) {
+	var (
+		data    Contenttransition
+		element Contentelement
+		rsp     = core.Response{Data: &data, Req: r}
+		vars    = mux.Vars(r)
+	)
+
+	if !rsp.IsJsonParseDone(r.Body) {
+		w.Write(rsp.Make())
+		return
+	}
+
+	App.DB.Preload("Contenttags").First(&element, vars["id"])
+
+	t, found := Editorial.find(data.Transition)
+
+	if element.ID == 0 || !readable(r, element) {
+		rsp.Errors.Add("ID", "Contentelement not found")
+	} else if !found {
+		rsp.Errors.Add("transition", "Unknown transition "+data.Transition)
+	} else if !canChange(r, "element.transition", element) || !t.allows(viewerOf(r)) {
+		rsp.Errors.Add("transition", "Not allowed to apply transition "+t.Name)
+	} else if !hasState(element.Status, t.From) {
+		rsp.Errors.Add("transition", "Transition "+t.Name+" is not allowed from "+element.Status)
+	} else {
+		userID, _ := strconv.Atoi(r.Header.Get("id"))
+		applyTransition(&element, t, userID, data.Comment)
+	}
+
+	rsp.Data = &element
+
+	w.Write(rsp.Make())
+}
+
+func actionTransitions(w http.ResponseWriter, r *http.Request) {
+	var (
+		element Contentelement
+		list    Contenttransitions
+		rsp     = core.Response{Data: &list, Req: r}
+		vars    = mux.Vars(r)
+	)
+
+	App.DB.First(&element, vars["id"])
+
+	if element.ID == 0 {
+		rsp.Errors.Add("ID", "Contentelement not found")
+	} else {
+		App.DB.Where("contentelement_id = ?", element.ID).Order("id DESC").Find(&list)
+	}
+
+	rsp.Data = &list
+
+	w.Write(rsp.Make())
+}
+
+func actionWorkflow(w http.ResponseWriter, r *http.Request) {
+	var (
+		rsp = core.Response{Data: &Editorial, Req: r}
+	)
+
+	w.Write(rsp.Make())
+}
@@ -0,0 +1,140 @@
+package contentelements_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"testing"
+
+	"github.com/go-rest-framework/contentelements"
+	"github.com/go-rest-framework/core"
+	"github.com/icrowley/fake"
+)
+
+type TestContenttransitions struct {
+	Errors []core.ErrorMsg                    `json:"errors"`
+	Data   contentelements.Contenttransitions `json:"data"`
+}
+
+func readTransitionsBody(r *http.Response, t *testing.T) TestContenttransitions {
+	var u TestContenttransitions
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		log.Fatal(err)
+	}
+	json.Unmarshal([]byte(body), &u)
+	return u
+}
+
+func transitionElement(t *testing.T, id uint, transition, token string) TestContentelement {
+	url := fmt.Sprintf("%s/%d/transition", Murl, id)
+
+	resp := doRequest(url, "POST", fmt.Sprintf(`{"transition":"%s","comment":"%s"}`, transition, fake.Word()), token)
+
+	if resp.StatusCode != 200 {
+		t.Errorf("Success expected: %d", resp.StatusCode)
+	}
+
+	return readElementBody(resp, t)
+}
+
+func TestWorkflow(t *testing.T) {
+	el := &contentelements.Contentelement{
+		Title:  fake.Title(),
+		Kind:   "standart",
+		Status: "draft",
+	}
+
+	uj, err := json.Marshal(el)
+	if err != nil {
+		fmt.Printf("Error: %s", err)
+		return
+	}
+
+	resp := doRequest(Murl, "POST", string(uj), AdminToken)
+
+	if resp.StatusCode != 200 {
+		t.Errorf("Success expected: %d", resp.StatusCode)
+	}
+
+	u := readElementBody(resp, t)
+
+	if len(u.Errors) != 0 {
+		t.Fatal(u.Errors)
+	}
+
+	id := u.Data.ID
+
+	u = transitionElement(t, id, "approve", AdminToken)
+
+	if len(u.Errors) == 0 {
+		t.Errorf("Approve from draft is not rejected")
+	}
+
+	u = transitionElement(t, id, "submit", UserToken)
+
+	if len(u.Errors) == 0 {
+		t.Errorf("Submit of another user's element is not rejected")
+	}
+
+	u = transitionElement(t, id, "submit", AdminToken)
+
+	if len(u.Errors) != 0 {
+		t.Fatal(u.Errors)
+	}
+
+	if u.Data.Status != "review" {
+		t.Errorf("Wrong status after submit: %s", u.Data.Status)
+	}
+
+	u = transitionElement(t, id, "approve", UserToken)
+
+	if len(u.Errors) == 0 {
+		t.Errorf("Approve by user is not rejected")
+	}
+
+	u = transitionElement(t, id, "approve", AdminToken)
+
+	if len(u.Errors) != 0 {
+		t.Fatal(u.Errors)
+	}
+
+	if u.Data.Status != "active" {
+		t.Errorf("Wrong status after approve: %s", u.Data.Status)
+	}
+
+	current := getElement(t, id)
+	current.Status = "draft"
+
+	uj, err = json.Marshal(current)
+	if err != nil {
+		fmt.Printf("Error: %s", err)
+		return
+	}
+
+	resp = doRequest(fmt.Sprintf("%s/%d", Murl, id), "PATCH", string(uj), AdminToken)
+
+	u = readElementBody(resp, t)
+
+	if len(u.Errors) == 0 {
+		t.Errorf("Status change without transition is not rejected")
+	}
+
+	resp = doRequest(fmt.Sprintf("%s/%d/transitions", Murl, id), "GET", "", AdminToken)
+
+	if resp.StatusCode != 200 {
+		t.Errorf("Success expected: %d", resp.StatusCode)
+	}
+
+	audit := readTransitionsBody(resp, t)
+
+	if len(audit.Data) != 2 || audit.Data[0].To != "active" || audit.Data[1].From != "draft" {
+		t.Errorf("Wrong transitions audit: %+v", audit.Data)
+	}
+
+	deleteElement(t, id)
+
+	return
+}